@@ -1,12 +1,7 @@
 package relay
 
 import (
-	"encoding/base64"
-	"errors"
-	"fmt"
 	"reflect"
-	"strconv"
-	"strings"
 )
 
 const PREFIX = "arrayconnection:"
@@ -19,9 +14,11 @@ type ArraySliceMetaInfo struct {
 /*
 A simple function that accepts an array and connection arguments, and returns
 a connection object for use in GraphQL. It uses array offsets as pagination,
-so pagination will only work if the array is static.
+so pagination will only work if the array is static. Use
+`ConnectionFromKeyset` to paginate by sort keys instead, or a SnapshotStore
+to paginate through a copy of the array.
 */
-func ConnectionFromArray(data []interface{}, args ConnectionArguments) *Connection {
+func ConnectionFromArray(data []interface{}, args ConnectionArguments, opts ...ConnectionOptions) *Connection {
 	return ConnectionFromArraySlice(
 		data,
 		args,
@@ -29,6 +26,7 @@ func ConnectionFromArray(data []interface{}, args ConnectionArguments) *Connecti
 			SliceStart:  0,
 			ArrayLength: len(data),
 		},
+		opts...,
 	)
 }
 
@@ -45,35 +43,27 @@ func ConnectionFromArraySlice(
 	arraySlice []interface{},
 	args ConnectionArguments,
 	meta ArraySliceMetaInfo,
+	opts ...ConnectionOptions,
 ) *Connection {
-	sliceEnd := meta.SliceStart + len(arraySlice)
-	beforeOffset := GetOffsetWithDefault(args.Before, meta.ArrayLength)
-	afterOffset := GetOffsetWithDefault(args.After, -1)
-
-	startOffset := ternaryMax(meta.SliceStart-1, afterOffset, -1) + 1
-	endOffset := ternaryMin(sliceEnd, beforeOffset, meta.ArrayLength)
-
-	if args.First != -1 {
-		endOffset = min(endOffset, startOffset+args.First)
-	}
-
-	if args.Last != -1 {
-		startOffset = max(startOffset, endOffset-args.Last)
-	}
-
-	begin := max(startOffset-meta.SliceStart, 0)
-	end := len(arraySlice) - (sliceEnd - endOffset)
-
-	if begin > end {
-		return NewConnection()
+	options := mergeConnectionOptions(opts)
+	codec := options.cursorCodec()
+	args = options.PaginationPolicy.clamp(args)
+	window := newArraySliceWindow(len(arraySlice), args, meta, options)
+	if window.empty() {
+		conn := NewConnection()
+		conn.TotalCount = &meta.ArrayLength
+		if options.PageCursors {
+			conn.PageCursors = window.pageCursors(args, meta, options)
+		}
+		return conn
 	}
 
-	slice := arraySlice[begin:end]
+	slice := arraySlice[window.begin:window.end]
 
 	edges := []*Edge{}
 	for index, value := range slice {
 		edges = append(edges, &Edge{
-			Cursor: OffsetToCursor(startOffset + index),
+			Cursor: codec.EncodeCursor(window.startOffset + index),
 			Node:   value,
 		})
 	}
@@ -84,6 +74,45 @@ func ConnectionFromArraySlice(
 		lastEdgeCursor = edges[len(edges)-1:][0].Cursor
 	}
 
+	conn := NewConnection()
+	conn.Edges = edges
+	conn.PageInfo = window.pageInfo(firstEdgeCursor, lastEdgeCursor)
+	conn.TotalCount = &meta.ArrayLength
+	if options.PageCursors {
+		conn.PageCursors = window.pageCursors(args, meta, options)
+	}
+
+	return conn
+}
+
+// arraySliceWindow holds the offset math shared by the connection helpers:
+// which part of the fetched slice falls inside the requested page, and
+// whether there are more items on either side of it.
+type arraySliceWindow struct {
+	startOffset     int
+	endOffset       int
+	begin           int
+	end             int
+	hasPreviousPage bool
+	hasNextPage     bool
+}
+
+func newArraySliceWindow(sliceLength int, args ConnectionArguments, meta ArraySliceMetaInfo, options ConnectionOptions) arraySliceWindow {
+	sliceEnd := meta.SliceStart + sliceLength
+	beforeOffset := GetOffsetWithDefault(args.Before, meta.ArrayLength, options)
+	afterOffset := GetOffsetWithDefault(args.After, -1, options)
+
+	startOffset := ternaryMax(meta.SliceStart-1, afterOffset, -1) + 1
+	endOffset := ternaryMin(sliceEnd, beforeOffset, meta.ArrayLength)
+
+	if args.First != -1 {
+		endOffset = min(endOffset, startOffset+args.First)
+	}
+
+	if args.Last != -1 {
+		startOffset = max(startOffset, endOffset-args.Last)
+	}
+
 	lowerBound := 0
 	if len(args.After) > 0 {
 		lowerBound = afterOffset + 1
@@ -104,44 +133,74 @@ func ConnectionFromArraySlice(
 		hasNextPage = endOffset < upperBound
 	}
 
-	conn := NewConnection()
-	conn.Edges = edges
-	conn.PageInfo = PageInfo{
-		StartCursor:     firstEdgeCursor,
-		EndCursor:       lastEdgeCursor,
-		HasPreviousPage: hasPreviousPage,
-		HasNextPage:     hasNextPage,
+	if options.BidirectionalPageInfo {
+		hasPreviousPage = hasPreviousPage || startOffset > 0
+		hasNextPage = hasNextPage || endOffset < meta.ArrayLength
+	}
+
+	return arraySliceWindow{
+		startOffset:     startOffset,
+		endOffset:       endOffset,
+		begin:           max(startOffset-meta.SliceStart, 0),
+		end:             sliceLength - (sliceEnd - endOffset),
+		hasPreviousPage: hasPreviousPage,
+		hasNextPage:     hasNextPage,
 	}
+}
+
+func (w arraySliceWindow) empty() bool {
+	return w.begin > w.end
+}
+
+func (w arraySliceWindow) pageInfo(startCursor, endCursor ConnectionCursor) PageInfo {
+	return PageInfo{
+		StartCursor:     startCursor,
+		EndCursor:       endCursor,
+		HasPreviousPage: w.hasPreviousPage,
+		HasNextPage:     w.hasNextPage,
+	}
+}
 
+// A node together with the data of the edge that leads to it.
+type NodeWithEdgeData struct {
+	Node     interface{} `json:"node"`
+	EdgeData interface{} `json:"edgeData"`
+}
+
+/*
+Like `ConnectionFromArray`, but takes the edge data along with each node and
+returns it in `Edge.EdgeData`, so that the extra edge fields of the
+connection resolve from it.
+*/
+func ConnectionFromArrayWithEdgeData(data []NodeWithEdgeData, args ConnectionArguments, opts ...ConnectionOptions) *Connection {
+	items := make([]interface{}, 0, len(data))
+	for _, item := range data {
+		items = append(items, item)
+	}
+	conn := ConnectionFromArray(items, args, opts...)
+	for _, edge := range conn.Edges {
+		item := edge.Node.(NodeWithEdgeData)
+		edge.Node = item.Node
+		edge.EdgeData = item.EdgeData
+	}
 	return conn
 }
 
-// Creates the cursor string from an offset
+// Creates the cursor string from an offset, using the default cursor format.
 func OffsetToCursor(offset int) ConnectionCursor {
-	str := fmt.Sprintf("%v%v", PREFIX, offset)
-	return ConnectionCursor(base64.StdEncoding.EncodeToString([]byte(str)))
+	return DefaultCursorCodec.EncodeCursor(offset)
 }
 
-// Re-derives the offset from the cursor string.
+// Re-derives the offset from the cursor string, using the default cursor format.
 func CursorToOffset(cursor ConnectionCursor) (int, error) {
-	str := ""
-	b, err := base64.StdEncoding.DecodeString(string(cursor))
-	if err == nil {
-		str = string(b)
-	}
-	str = strings.Replace(str, PREFIX, "", -1)
-	offset, err := strconv.Atoi(str)
-	if err != nil {
-		return 0, errors.New("Invalid cursor")
-	}
-	return offset, nil
+	return DefaultCursorCodec.DecodeCursor(cursor)
 }
 
 // Return the cursor associated with an object in an array.
-func CursorForObjectInConnection(data []interface{}, object interface{}) ConnectionCursor {
+func CursorForObjectInConnection(data []interface{}, object interface{}, opts ...ConnectionOptions) ConnectionCursor {
 	offset := -1
 	for i, d := range data {
-		// TODO: better object comparison
+		// see CursorForObjectInConnectionByKey to compare objects by key
 		if reflect.DeepEqual(d, object) {
 			offset = i
 			break
@@ -150,14 +209,67 @@ func CursorForObjectInConnection(data []interface{}, object interface{}) Connect
 	if offset == -1 {
 		return ""
 	}
-	return OffsetToCursor(offset)
+	return mergeConnectionOptions(opts).cursorCodec().EncodeCursor(offset)
+}
+
+// Returns a key identifying an object, such as its ID. Keys are compared
+// with `==` and used as map keys, so keys that are not comparable, such as
+// slices or maps, panic.
+type ObjectKeyFn func(object interface{}) interface{}
+
+// Maps the keys of the objects of an array to their offsets.
+type OffsetIndex map[interface{}]int
+
+// Builds the index of an array, for use with CursorForObjectInConnectionByKey.
+func NewOffsetIndex(data []interface{}, key ObjectKeyFn) OffsetIndex {
+	index := OffsetIndex{}
+	for i, d := range data {
+		k := key(d)
+		if _, ok := index[k]; !ok {
+			index[k] = i
+		}
+	}
+	return index
+}
+
+/*
+Return the cursor associated with an object in an array, comparing objects
+by the key returned by `key` instead of by deep equality, so that an object
+whose fields changed is still found.
+
+If `index` is not nil, the offset is looked up in it in constant time. When
+`data` is given too, the object found at that offset is checked against the
+key, and `data` is scanned if the index is stale because the array was
+mutated since it was built, or does not hold the key; rebuild the index after
+mutations to keep lookups in constant time. When `data` is nil, the index is
+trusted as it is.
+*/
+func CursorForObjectInConnectionByKey(data []interface{}, object interface{}, key ObjectKeyFn, index OffsetIndex, opts ...ConnectionOptions) ConnectionCursor {
+	k := key(object)
+	offset := -1
+	if i, ok := index[k]; ok && (data == nil || (i < len(data) && key(data[i]) == k)) {
+		offset = i
+	} else {
+		for i, d := range data {
+			if key(d) == k {
+				offset = i
+				break
+			}
+		}
+	}
+	if offset == -1 {
+		return ""
+	}
+	return mergeConnectionOptions(opts).cursorCodec().EncodeCursor(offset)
 }
 
-func GetOffsetWithDefault(cursor ConnectionCursor, defaultOffset int) int {
+// Returns the offset encoded in the cursor, or defaultOffset if the cursor
+// is empty or cannot be decoded.
+func GetOffsetWithDefault(cursor ConnectionCursor, defaultOffset int, opts ...ConnectionOptions) int {
 	if cursor == "" {
 		return defaultOffset
 	}
-	offset, err := CursorToOffset(cursor)
+	offset, err := mergeConnectionOptions(opts).cursorCodec().DecodeCursor(cursor)
 	if err != nil {
 		return defaultOffset
 	}
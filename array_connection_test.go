@@ -12,6 +12,10 @@ var arrayConnectionTestLetters = []interface{}{
 	"A", "B", "C", "D", "E",
 }
 
+func arrayConnectionTestTotalCount(count int) *int {
+	return &count
+}
+
 func TestConnectionFromArray_HandlesBasicSlicing_ReturnsAllElementsWithoutFilters(t *testing.T) {
 	args := relay.NewConnectionArguments(nil)
 
@@ -44,6 +48,7 @@ func TestConnectionFromArray_HandlesBasicSlicing_ReturnsAllElementsWithoutFilter
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -80,6 +85,7 @@ func TestConnectionFromArray_HandlesBasicSlicing_RespectsASmallerFirst(t *testin
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -123,6 +129,7 @@ func TestConnectionFromArray_HandlesBasicSlicing_RespectsAnOverlyLargeFirst(t *t
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -154,6 +161,7 @@ func TestConnectionFromArray_HandlesBasicSlicing_RespectsASmallerLast(t *testing
 			HasPreviousPage: true,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -197,6 +205,7 @@ func TestConnectionFromArray_HandlesBasicSlicing_RespectsAnOverlyLargeLast(t *te
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -230,6 +239,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsFirstAndAfter(t *testing.
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -266,6 +276,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsFirstAndAfterWithLongFirs
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -297,6 +308,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsLastAndBefore(t *testing.
 			HasPreviousPage: true,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -332,6 +344,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsLastAndBeforeWithLongLast
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -364,6 +377,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsFirstAndAfterAndBefore_To
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -400,6 +414,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsFirstAndAfterAndBefore_To
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -436,6 +451,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsFirstAndAfterAndBefore_Ex
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -468,6 +484,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsLastAndAfterAndBefore_Too
 			HasPreviousPage: true,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -504,6 +521,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsLasttAndAfterAndBefore_To
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -540,6 +558,7 @@ func TestConnectionFromArray_HandlesPagination_RespectsLastAndAfterAndBefore_Exa
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -560,6 +579,7 @@ func TestConnectionFromArray_HandlesCursorEdgeCases_ReturnsNoElementsIfFirstIsZe
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -603,6 +623,7 @@ func TestConnectionFromArray_HandlesCursorEdgeCases_ReturnsAllElementsIfCursorsA
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -646,6 +667,7 @@ func TestConnectionFromArray_HandlesCursorEdgeCases_ReturnsAllElementsIfCursorsA
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -662,8 +684,9 @@ func TestConnectionFromArray_HandlesCursorEdgeCases_ReturnsNullIfCursorsIsConsec
 	args := relay.NewConnectionArguments(filter)
 
 	expected := &relay.Connection{
-		Edges:    []*relay.Edge{},
-		PageInfo: relay.PageInfo{},
+		Edges:      []*relay.Edge{},
+		PageInfo:   relay.PageInfo{},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -679,8 +702,9 @@ func TestConnectionFromArray_HandlesCursorEdgeCases_ReturnsNoElementsIfCursorsCr
 	args := relay.NewConnectionArguments(filter)
 
 	expected := &relay.Connection{
-		Edges:    []*relay.Edge{},
-		PageInfo: relay.PageInfo{},
+		Edges:      []*relay.Edge{},
+		PageInfo:   relay.PageInfo{},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
@@ -726,6 +750,7 @@ func TestConnectionFromArraySlice_JustRightArraySlice(t *testing.T) {
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArraySlice(
@@ -765,6 +790,7 @@ func TestConnectionFromArraySlice_OversizedSliceLeft(t *testing.T) {
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArraySlice(
@@ -800,6 +826,7 @@ func TestConnectionFromArraySlice_OversizedSliceRight(t *testing.T) {
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArraySlice(
@@ -835,6 +862,7 @@ func TestConnectionFromArraySlice_OversizedSliceBoth(t *testing.T) {
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArraySlice(
@@ -874,6 +902,7 @@ func TestConnectionFromArraySlice_UndersizedSliceLeft(t *testing.T) {
 			HasPreviousPage: false,
 			HasNextPage:     false,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArraySlice(
@@ -913,6 +942,7 @@ func TestConnectionFromArraySlice_UndersizedSliceRight(t *testing.T) {
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArraySlice(
@@ -948,6 +978,7 @@ func TestConnectionFromArraySlice_UndersizedSliceBoth(t *testing.T) {
 			HasPreviousPage: false,
 			HasNextPage:     true,
 		},
+		TotalCount: arrayConnectionTestTotalCount(5),
 	}
 
 	result := relay.ConnectionFromArraySlice(
@@ -962,3 +993,132 @@ func TestConnectionFromArraySlice_UndersizedSliceBoth(t *testing.T) {
 		t.Fatalf("wrong result, connection result diff: %v", testutil.Diff(expected, result))
 	}
 }
+
+func TestConnectionFromArray_BidirectionalPageInfo_HasPreviousPageWhenPaginatingForwards(t *testing.T) {
+	filter := map[string]interface{}{
+		"first": 2,
+		"after": "YXJyYXljb25uZWN0aW9uOjA=",
+	}
+	args := relay.NewConnectionArguments(filter)
+
+	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args, relay.ConnectionOptions{
+		BidirectionalPageInfo: true,
+	})
+	expected := relay.PageInfo{
+		StartCursor:     "YXJyYXljb25uZWN0aW9uOjE=",
+		EndCursor:       "YXJyYXljb25uZWN0aW9uOjI=",
+		HasPreviousPage: true,
+		HasNextPage:     true,
+	}
+	if !reflect.DeepEqual(result.PageInfo, expected) {
+		t.Fatalf("wrong result, page info diff: %v", testutil.Diff(expected, result.PageInfo))
+	}
+}
+func TestConnectionFromArray_BidirectionalPageInfo_HasNextPageWhenPaginatingBackwards(t *testing.T) {
+	filter := map[string]interface{}{
+		"last":   2,
+		"before": "YXJyYXljb25uZWN0aW9uOjQ=",
+	}
+	args := relay.NewConnectionArguments(filter)
+
+	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args, relay.ConnectionOptions{
+		BidirectionalPageInfo: true,
+	})
+	expected := relay.PageInfo{
+		StartCursor:     "YXJyYXljb25uZWN0aW9uOjI=",
+		EndCursor:       "YXJyYXljb25uZWN0aW9uOjM=",
+		HasPreviousPage: true,
+		HasNextPage:     true,
+	}
+	if !reflect.DeepEqual(result.PageInfo, expected) {
+		t.Fatalf("wrong result, page info diff: %v", testutil.Diff(expected, result.PageInfo))
+	}
+}
+func TestConnectionFromArrayWithEdgeData_ReturnsEdgeData(t *testing.T) {
+	data := []relay.NodeWithEdgeData{
+		{Node: "A", EdgeData: 1},
+		{Node: "B", EdgeData: 2},
+		{Node: "C", EdgeData: 3},
+	}
+	filter := map[string]interface{}{
+		"first": 1,
+		"after": "YXJyYXljb25uZWN0aW9uOjA=",
+	}
+	args := relay.NewConnectionArguments(filter)
+
+	expected := []*relay.Edge{
+		&relay.Edge{
+			Node:     "B",
+			Cursor:   "YXJyYXljb25uZWN0aW9uOjE=",
+			EdgeData: 2,
+		},
+	}
+	result := relay.ConnectionFromArrayWithEdgeData(data, args)
+	if !reflect.DeepEqual(result.Edges, expected) {
+		t.Fatalf("wrong result, edges result diff: %v", testutil.Diff(expected, result.Edges))
+	}
+}
+
+func userKey(object interface{}) interface{} {
+	return object.(*user).ID
+}
+
+func TestConnectionFromArray_CursorForObjectInConnectionByKey_FindsChangedObjects(t *testing.T) {
+	users := []interface{}{
+		&user{ID: 1, Name: "Dan"},
+		&user{ID: 2, Name: "Nick"},
+		&user{ID: 3, Name: "Lee"},
+	}
+	// the object was renamed by a mutation, so it is no longer deeply equal
+	renamed := &user{ID: 2, Name: "Nicholas"}
+
+	if cursor := relay.CursorForObjectInConnection(users, renamed); cursor != "" {
+		t.Fatalf("expected deep equality to miss the renamed object, got: %v", cursor)
+	}
+	expected := relay.ConnectionCursor("YXJyYXljb25uZWN0aW9uOjE=")
+	if cursor := relay.CursorForObjectInConnectionByKey(users, renamed, userKey, nil); cursor != expected {
+		t.Fatalf("wrong cursor from scan, expected: %v, got: %v", expected, cursor)
+	}
+	index := relay.NewOffsetIndex(users, userKey)
+	if cursor := relay.CursorForObjectInConnectionByKey(nil, renamed, userKey, index); cursor != expected {
+		t.Fatalf("wrong cursor from index, expected: %v, got: %v", expected, cursor)
+	}
+}
+func TestConnectionFromArray_CursorForObjectInConnectionByKey_ScansWhenTheIndexIsStale(t *testing.T) {
+	users := []interface{}{
+		&user{ID: 1, Name: "Dan"},
+		&user{ID: 2, Name: "Nick"},
+	}
+	index := relay.NewOffsetIndex(users, userKey)
+
+	// a user is inserted at the start of the array after the index was built
+	users = append([]interface{}{&user{ID: 3, Name: "Lee"}}, users...)
+	expected := relay.ConnectionCursor("YXJyYXljb25uZWN0aW9uOjI=")
+	if cursor := relay.CursorForObjectInConnectionByKey(users, &user{ID: 2}, userKey, index); cursor != expected {
+		t.Fatalf("wrong cursor from stale index, expected: %v, got: %v", expected, cursor)
+	}
+	expected = relay.ConnectionCursor("YXJyYXljb25uZWN0aW9uOjA=")
+	if cursor := relay.CursorForObjectInConnectionByKey(users, &user{ID: 3}, userKey, index); cursor != expected {
+		t.Fatalf("wrong cursor for key missing from the index, expected: %v, got: %v", expected, cursor)
+	}
+
+	// a user is deleted, leaving an offset past the end of the array
+	users = users[:1]
+	if cursor := relay.CursorForObjectInConnectionByKey(users, &user{ID: 2}, userKey, index); cursor != "" {
+		t.Fatalf("wrong result, expected empty cursor, got: %v", cursor)
+	}
+}
+func TestConnectionFromArray_CursorForObjectInConnectionByKey_ReturnsEmptyCursor_GivenANonMemberObject(t *testing.T) {
+	users := []interface{}{
+		&user{ID: 1, Name: "Dan"},
+	}
+	index := relay.NewOffsetIndex(users, userKey)
+	stranger := &user{ID: 4, Name: "Joe"}
+
+	if cursor := relay.CursorForObjectInConnectionByKey(users, stranger, userKey, nil); cursor != "" {
+		t.Fatalf("wrong result, expected empty cursor, got: %v", cursor)
+	}
+	if cursor := relay.CursorForObjectInConnectionByKey(users, stranger, userKey, index); cursor != "" {
+		t.Fatalf("wrong result, expected empty cursor, got: %v", cursor)
+	}
+}
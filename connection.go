@@ -1,6 +1,15 @@
 package relay
 
-import "github.com/graphql-go/graphql"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+)
+
+// The `totalCount` field was queried on a connection that does not count its
+// items, such as keyset and streamed connections.
+var ErrTotalCountUnknown = errors.New("Total count of the connection is not known")
 
 /*
 Returns a GraphQLFieldConfigArgumentMap appropriate to include
@@ -33,6 +42,27 @@ type ConnectionConfig struct {
 	NodeType         *graphql.Object `json:"nodeType"`
 	EdgeFields       graphql.Fields  `json:"edgeFields"`
 	ConnectionFields graphql.Fields  `json:"connectionFields"`
+
+	// Adds a `totalCount: Int!` field, resolved from `Connection.TotalCount`;
+	// resolving it fails with ErrTotalCountUnknown for connections whose items
+	// are not counted, such as keyset and streamed connections.
+	TotalCount bool `json:"totalCount"`
+	// Adds a `nodes: [NodeType]` field listing the node of every edge.
+	Nodes bool `json:"nodes"`
+	// Adds a `pageCursors: PageCursors` field, resolved from
+	// `Connection.PageCursors`.
+	PageCursors bool `json:"pageCursors"`
+
+	// Loads the nodes of the edges, which then hold keys instead of
+	// objects, e.g. NodeDefinitions.LoadNode for global IDs.
+	NodeLoader LoadFn `json:"-"`
+
+	// Fields clients can sort the connection by, generating an
+	// `<Name>Order` enum and an `orderBy` argument.
+	OrderFields []string `json:"orderFields"`
+	// Fields clients can filter the connection by, generating an
+	// `<Name>Filter` input object and a `filter` argument.
+	FilterFields []FilterFieldConfig `json:"filterFields"`
 }
 
 type EdgeType struct {
@@ -42,6 +72,18 @@ type EdgeType struct {
 type GraphQLConnectionDefinitions struct {
 	EdgeType       *graphql.Object `json:"edgeType"`
 	ConnectionType *graphql.Object `json:"connectionType"`
+
+	// Set when `OrderFields` or `FilterFields` are configured.
+	OrderType  *graphql.Enum        `json:"orderType"`
+	FilterType *graphql.InputObject `json:"filterType"`
+
+	// The arguments for a field returning the connection: the connection
+	// arguments, plus `orderBy` and `filter` when configured. Use ParseArgs
+	// to read them in the resolver.
+	Args graphql.FieldConfigArgument `json:"args"`
+
+	filterFieldNames []string
+	filterConditions map[string]FilterCondition
 }
 
 /*
@@ -70,9 +112,62 @@ var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
 	},
 })
 
+/*
+The page cursor types used by connections with numbered pages.
+*/
+var pageCursorType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "PageCursor",
+	Description: "A cursor to a page of a connection.",
+	Fields: graphql.Fields{
+		"cursor": &graphql.Field{
+			Type:        graphql.String,
+			Description: "The cursor to pass as `after` to get the page, null for the first page.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if cursor, ok := p.Source.(*PageCursor); ok && cursor.Cursor != "" {
+					return cursor.Cursor, nil
+				}
+				return nil, nil
+			},
+		},
+		"page": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Int),
+			Description: "The page number, counting from 1.",
+		},
+		"isCurrent": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "Whether this is the page being returned.",
+		},
+	},
+})
+
+var pageCursorsType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "PageCursors",
+	Description: "Cursors to the pages of a connection, for numbered pagination.",
+	Fields: graphql.Fields{
+		"first": &graphql.Field{
+			Type:        pageCursorType,
+			Description: "The first page, when it is not in `around`.",
+		},
+		"around": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(pageCursorType))),
+			Description: "The pages around the current one.",
+		},
+		"last": &graphql.Field{
+			Type:        pageCursorType,
+			Description: "The last page, when it is not in `around`.",
+		},
+		"previous": &graphql.Field{
+			Type:        pageCursorType,
+			Description: "The page before the current one.",
+		},
+	},
+})
+
 /*
 Returns a GraphQLObjectType for a connection with the given name,
 and whose nodes are of the specified type.
+
+Edge fields without a resolve function are resolved from `Edge.EdgeData`.
 */
 
 func ConnectionDefinitions(config ConnectionConfig) *GraphQLConnectionDefinitions {
@@ -84,6 +179,7 @@ func ConnectionDefinitions(config ConnectionConfig) *GraphQLConnectionDefinition
 			"node": &graphql.Field{
 				Type:        config.NodeType,
 				Description: "The item at the end of the edge",
+				Resolve:     resolveEdgeNode(config.NodeLoader),
 			},
 			"cursor": &graphql.Field{
 				Type:        graphql.NewNonNull(graphql.String),
@@ -92,6 +188,11 @@ func ConnectionDefinitions(config ConnectionConfig) *GraphQLConnectionDefinition
 		},
 	})
 	for fieldName, fieldConfig := range config.EdgeFields {
+		if fieldConfig.Resolve == nil {
+			field := *fieldConfig
+			field.Resolve = resolveFromEdgeData
+			fieldConfig = &field
+		}
 		edgeType.AddFieldConfig(fieldName, fieldConfig)
 	}
 
@@ -110,12 +211,99 @@ func ConnectionDefinitions(config ConnectionConfig) *GraphQLConnectionDefinition
 			},
 		},
 	})
+	if config.TotalCount {
+		connectionType.AddFieldConfig("totalCount", &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Int),
+			Description: "The total number of items in the connection.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				conn, ok := p.Source.(*Connection)
+				if !ok {
+					return nil, nil
+				}
+				if conn.TotalCount == nil {
+					return nil, ErrTotalCountUnknown
+				}
+				return *conn.TotalCount, nil
+			},
+		})
+	}
+	if config.Nodes {
+		connectionType.AddFieldConfig("nodes", &graphql.Field{
+			Type:        graphql.NewList(config.NodeType),
+			Description: "A list of the nodes at the end of the edges.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				conn, ok := p.Source.(*Connection)
+				if !ok {
+					return nil, nil
+				}
+				nodes := []interface{}{}
+				for _, edge := range conn.Edges {
+					if config.NodeLoader != nil && edge.Node != nil {
+						nodes = append(nodes, config.NodeLoader(p.Context, fmt.Sprintf("%v", edge.Node)))
+					} else {
+						nodes = append(nodes, edge.Node)
+					}
+				}
+				return nodes, nil
+			},
+		})
+	}
+	if config.PageCursors {
+		connectionType.AddFieldConfig("pageCursors", &graphql.Field{
+			Type:        pageCursorsType,
+			Description: "Cursors to the pages of the connection.",
+		})
+	}
 	for fieldName, fieldConfig := range config.ConnectionFields {
 		connectionType.AddFieldConfig(fieldName, fieldConfig)
 	}
 
-	return &GraphQLConnectionDefinitions{
+	definitions := &GraphQLConnectionDefinitions{
 		EdgeType:       edgeType,
 		ConnectionType: connectionType,
 	}
+	args := graphql.FieldConfigArgument{}
+	if len(config.OrderFields) > 0 {
+		definitions.OrderType = newOrderEnum(config.Name, config.OrderFields)
+		args["orderBy"] = &graphql.ArgumentConfig{
+			Type:        graphql.NewList(graphql.NewNonNull(definitions.OrderType)),
+			Description: "The orderings to sort the items by, in order of precedence.",
+		}
+	}
+	if len(config.FilterFields) > 0 {
+		definitions.FilterType, definitions.filterFieldNames, definitions.filterConditions =
+			newFilterInputObject(config.Name, config.FilterFields)
+		args["filter"] = &graphql.ArgumentConfig{
+			Type:        definitions.FilterType,
+			Description: "The conditions the items must all meet.",
+		}
+	}
+	definitions.Args = NewConnectionArgs(args)
+	return definitions
+}
+
+// Resolves an edge field from the edge data, the way fields of the node are
+// resolved from the node.
+func resolveFromEdgeData(p graphql.ResolveParams) (interface{}, error) {
+	if edge, ok := p.Source.(*Edge); ok {
+		if edge.EdgeData == nil {
+			return nil, nil
+		}
+		p.Source = edge.EdgeData
+	}
+	return graphql.DefaultResolveFn(p)
+}
+
+// Resolves the node of an edge through the loader, if any.
+func resolveEdgeNode(loader LoadFn) graphql.FieldResolveFn {
+	if loader == nil {
+		return nil
+	}
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		edge, ok := p.Source.(*Edge)
+		if !ok || edge.Node == nil {
+			return nil, nil
+		}
+		return loader(p.Context, fmt.Sprintf("%v", edge.Node)), nil
+	}
 }
@@ -0,0 +1,133 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// The page size given in `first` or `last` is not a non-negative integer.
+	ErrInvalidPageSize = errors.New("must be a non-negative integer")
+	// The cursor given in `before` or `after` cannot be decoded.
+	ErrInvalidCursor = errors.New("is not a valid cursor")
+	// Both `first` and `last` were given while ConnectionOptions.RejectFirstAndLast is set.
+	ErrFirstAndLast = errors.New("cannot be combined with `first`")
+)
+
+/*
+Returned by NewValidatedConnectionArguments when a connection argument is
+invalid. `Err` is one of the Err* sentinels above, so resolvers can use
+`errors.Is` to map it to a GraphQL error. `Cause` is the error of the cursor
+codec for cursors it cannot decode, such as a *CursorSignatureError, and is
+matched by `errors.As`.
+*/
+type ConnectionArgumentError struct {
+	Argument string
+	Value    interface{}
+	Err      error
+	Cause    error
+}
+
+func (e *ConnectionArgumentError) Error() string {
+	return fmt.Sprintf("Argument `%v` %v", e.Argument, e.Err)
+}
+
+func (e *ConnectionArgumentError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+/*
+Like NewConnectionArguments, but returns a *ConnectionArgumentError instead of
+ignoring arguments that are not valid: negative or non-integer `first` and
+`last`, `before` and `after` cursors that the cursor codec cannot decode,
+page sizes rejected by the PaginationPolicy, and, if
+ConnectionOptions.RejectFirstAndLast is set, `first` and `last` given together.
+Empty cursors are treated as absent, like the cursor of the first page in
+PageCursors.
+*/
+func NewValidatedConnectionArguments(filters map[string]interface{}, opts ...ConnectionOptions) (ConnectionArguments, error) {
+	options := mergeConnectionOptions(opts)
+	conn := NewConnectionArguments(nil)
+
+	var err error
+	if conn.First, err = validatePageSize(filters, "first"); err != nil {
+		return conn, err
+	}
+	if conn.Last, err = validatePageSize(filters, "last"); err != nil {
+		return conn, err
+	}
+	if options.RejectFirstAndLast && conn.First != -1 && conn.Last != -1 {
+		return conn, &ConnectionArgumentError{Argument: "last", Value: conn.Last, Err: ErrFirstAndLast}
+	}
+	if conn.Before, err = validateCursor(filters, "before", options.cursorCodec()); err != nil {
+		return conn, err
+	}
+	if conn.After, err = validateCursor(filters, "after", options.cursorCodec()); err != nil {
+		return conn, err
+	}
+	return options.PaginationPolicy.Apply(conn)
+}
+
+func validatePageSize(filters map[string]interface{}, name string) (int, error) {
+	value, ok := filters[name]
+	if !ok || value == nil {
+		return -1, nil
+	}
+	v := reflect.ValueOf(value)
+	size := int64(-1)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = int64(v.Uint())
+	}
+	if size < 0 {
+		return -1, &ConnectionArgumentError{Argument: name, Value: value, Err: ErrInvalidPageSize}
+	}
+	return int(size), nil
+}
+
+func validateCursor(filters map[string]interface{}, name string, codec CursorCodec) (ConnectionCursor, error) {
+	value, ok := filters[name]
+	if !ok || value == nil || value == "" || value == ConnectionCursor("") {
+		return "", nil
+	}
+	var cursor ConnectionCursor
+	switch value := value.(type) {
+	case string:
+		cursor = ConnectionCursor(value)
+	case ConnectionCursor:
+		cursor = value
+	default:
+		return "", &ConnectionArgumentError{Argument: name, Value: value, Err: ErrInvalidCursor}
+	}
+	offset, err := codec.DecodeCursor(cursor)
+	if err != nil {
+		return "", &ConnectionArgumentError{Argument: name, Value: value, Err: ErrInvalidCursor, Cause: err}
+	}
+	if offset < 0 {
+		return "", &ConnectionArgumentError{Argument: name, Value: value, Err: ErrInvalidCursor}
+	}
+	return cursor, nil
+}
+
+/*
+Checks that the `before` and `after` cursors of the arguments can be decoded,
+for the helpers that return errors instead of ignoring invalid cursors.
+*/
+func validateCursors(args ConnectionArguments, codec CursorCodec) error {
+	filters := map[string]interface{}{
+		"before": args.Before,
+		"after":  args.After,
+	}
+	for _, name := range []string{"before", "after"} {
+		if _, err := validateCursor(filters, name, codec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package relay_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/relay"
+)
+
+func TestNewValidatedConnectionArguments_AcceptsValidArguments(t *testing.T) {
+	filter := map[string]interface{}{
+		"first":  2,
+		"after":  "YXJyYXljb25uZWN0aW9uOjE=",
+		"before": "YXJyYXljb25uZWN0aW9uOjQ=",
+	}
+	args, err := relay.NewValidatedConnectionArguments(filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := relay.ConnectionArguments{
+		First:  2,
+		Last:   -1,
+		After:  "YXJyYXljb25uZWN0aW9uOjE=",
+		Before: "YXJyYXljb25uZWN0aW9uOjQ=",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("wrong result, expected: %v, got: %v", expected, args)
+	}
+
+	args, err = relay.NewValidatedConnectionArguments(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(args, relay.NewConnectionArguments(nil)) {
+		t.Fatalf("wrong result for empty arguments, got: %v", args)
+	}
+}
+func TestNewValidatedConnectionArguments_RejectsInvalidArguments(t *testing.T) {
+	tests := []struct {
+		filter   map[string]interface{}
+		argument string
+		err      error
+	}{
+		{map[string]interface{}{"first": -1}, "first", relay.ErrInvalidPageSize},
+		{map[string]interface{}{"first": "10"}, "first", relay.ErrInvalidPageSize},
+		{map[string]interface{}{"last": -5}, "last", relay.ErrInvalidPageSize},
+		{map[string]interface{}{"last": 1.5}, "last", relay.ErrInvalidPageSize},
+		{map[string]interface{}{"after": "invalid"}, "after", relay.ErrInvalidCursor},
+		{map[string]interface{}{"before": 3}, "before", relay.ErrInvalidCursor},
+		{map[string]interface{}{"before": string(relay.OffsetToCursor(-1))}, "before", relay.ErrInvalidCursor},
+	}
+	for _, test := range tests {
+		_, err := relay.NewValidatedConnectionArguments(test.filter)
+		argErr, ok := err.(*relay.ConnectionArgumentError)
+		if !ok {
+			t.Fatalf("expected ConnectionArgumentError for %v, got: %v", test.filter, err)
+		}
+		if argErr.Argument != test.argument || !errors.Is(err, test.err) {
+			t.Fatalf("wrong error for %v, got: %v", test.filter, err)
+		}
+	}
+}
+func TestNewValidatedConnectionArguments_OptionallyRejectsFirstAndLast(t *testing.T) {
+	filter := map[string]interface{}{
+		"first": 2,
+		"last":  1,
+	}
+	if _, err := relay.NewValidatedConnectionArguments(filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := relay.NewValidatedConnectionArguments(filter, relay.ConnectionOptions{
+		RejectFirstAndLast: true,
+	})
+	if !errors.Is(err, relay.ErrFirstAndLast) {
+		t.Fatalf("expected ErrFirstAndLast, got: %v", err)
+	}
+	if err.Error() != "Argument `last` cannot be combined with `first`" {
+		t.Fatalf("wrong error message, got: %v", err)
+	}
+}
+func TestNewValidatedConnectionArguments_DecodesCursorsWithCodec(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, relay.Key{ID: "k1", Secret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options := relay.ConnectionOptions{
+		CursorCodec: codec,
+	}
+	filter := map[string]interface{}{
+		"after": string(codec.EncodeCursor(1)),
+	}
+	if _, err := relay.NewValidatedConnectionArguments(filter, options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filter = map[string]interface{}{
+		"after": string(relay.OffsetToCursor(1)),
+	}
+	if _, err := relay.NewValidatedConnectionArguments(filter, options); !errors.Is(err, relay.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for unsigned cursor, got: %v", err)
+	}
+}
+func TestNewValidatedConnectionArguments_KeepsTheCodecError(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, relay.Key{ID: "k1", Secret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	forger, err := relay.NewSignedCursorCodec(nil, relay.Key{ID: "k1", Secret: []byte("guessed")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filter := map[string]interface{}{
+		"after": string(forger.EncodeCursor(100)),
+	}
+	_, err = relay.NewValidatedConnectionArguments(filter, relay.ConnectionOptions{
+		CursorCodec: codec,
+	})
+	if !errors.Is(err, relay.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for forged cursor, got: %v", err)
+	}
+	var signatureErr *relay.CursorSignatureError
+	if !errors.As(err, &signatureErr) || signatureErr.Reason != "signature mismatch" {
+		t.Fatalf("expected *CursorSignatureError for forged cursor, got: %v", err)
+	}
+	if err.Error() != "Argument `after` is not a valid cursor" {
+		t.Fatalf("wrong error message, got: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package relay
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/graphql-go/graphql"
+)
+
+type FilterOperator string
+
+const (
+	FilterEq       FilterOperator = "eq"
+	FilterNe       FilterOperator = "ne"
+	FilterLt       FilterOperator = "lt"
+	FilterLte      FilterOperator = "lte"
+	FilterGt       FilterOperator = "gt"
+	FilterGte      FilterOperator = "gte"
+	FilterIn       FilterOperator = "in"
+	FilterContains FilterOperator = "contains"
+)
+
+/*
+A field of the nodes that clients can filter a connection by.
+
+Each operator adds a field to the `<Name>Filter` input object, named after
+the field and the operator, e.g. `ageGt: Int`. The `in` operator takes a list
+of values.
+*/
+type FilterFieldConfig struct {
+	Name      string           `json:"name"`
+	Type      graphql.Input    `json:"type"`
+	Operators []FilterOperator `json:"operators"`
+}
+
+// A sort key parsed from the `orderBy` argument.
+type OrderBy struct {
+	Field      string `json:"field"`
+	Descending bool   `json:"descending"`
+}
+
+// A condition parsed from the `filter` argument.
+type FilterCondition struct {
+	Field    string         `json:"field"`
+	Operator FilterOperator `json:"operator"`
+	Value    interface{}    `json:"value"`
+}
+
+// The arguments of a connection field with sorting and filtering.
+type ConnectionQueryArguments struct {
+	ConnectionArguments
+	OrderBy []OrderBy         `json:"orderBy"`
+	Filter  []FilterCondition `json:"filter"`
+}
+
+/*
+Parses the arguments of a field using `Args`: the connection arguments are
+validated as by NewValidatedConnectionArguments, and the sort keys and filter
+conditions are returned in the order they were declared.
+*/
+func (d *GraphQLConnectionDefinitions) ParseArgs(args map[string]interface{}, opts ...ConnectionOptions) (ConnectionQueryArguments, error) {
+	parsed := ConnectionQueryArguments{
+		OrderBy: []OrderBy{},
+		Filter:  []FilterCondition{},
+	}
+	connectionArgs, err := NewValidatedConnectionArguments(args, opts...)
+	if err != nil {
+		return parsed, err
+	}
+	parsed.ConnectionArguments = connectionArgs
+
+	if orderBy, ok := args["orderBy"].([]interface{}); ok {
+		for _, order := range orderBy {
+			if order, ok := order.(OrderBy); ok {
+				parsed.OrderBy = append(parsed.OrderBy, order)
+			}
+		}
+	}
+	if filter, ok := args["filter"].(map[string]interface{}); ok {
+		for _, name := range d.filterFieldNames {
+			value, ok := filter[name]
+			if !ok {
+				continue
+			}
+			condition := d.filterConditions[name]
+			condition.Value = value
+			parsed.Filter = append(parsed.Filter, condition)
+		}
+	}
+	return parsed, nil
+}
+
+func newOrderEnum(name string, fields []string) *graphql.Enum {
+	values := graphql.EnumValueConfigMap{}
+	for _, field := range fields {
+		values[enumValueName(field)+"_ASC"] = &graphql.EnumValueConfig{
+			Value:       OrderBy{Field: field},
+			Description: "Sort by " + field + " in ascending order.",
+		}
+		values[enumValueName(field)+"_DESC"] = &graphql.EnumValueConfig{
+			Value:       OrderBy{Field: field, Descending: true},
+			Description: "Sort by " + field + " in descending order.",
+		}
+	}
+	return graphql.NewEnum(graphql.EnumConfig{
+		Name:        name + "Order",
+		Description: "The orderings of a " + name + " connection.",
+		Values:      values,
+	})
+}
+
+// Returns the filter input object, its fields in declaration order, and the
+// condition each field stands for.
+func newFilterInputObject(name string, fields []FilterFieldConfig) (*graphql.InputObject, []string, map[string]FilterCondition) {
+	inputFields := graphql.InputObjectConfigFieldMap{}
+	names := []string{}
+	conditions := map[string]FilterCondition{}
+	for _, field := range fields {
+		for _, operator := range field.Operators {
+			fieldName := field.Name + strings.ToUpper(string(operator[:1])) + string(operator[1:])
+			var fieldType graphql.Input = field.Type
+			if operator == FilterIn {
+				fieldType = graphql.NewList(graphql.NewNonNull(field.Type))
+			}
+			inputFields[fieldName] = &graphql.InputObjectFieldConfig{
+				Type: fieldType,
+			}
+			names = append(names, fieldName)
+			conditions[fieldName] = FilterCondition{
+				Field:    field.Name,
+				Operator: operator,
+			}
+		}
+	}
+	inputObject := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        name + "Filter",
+		Description: "The conditions to filter a " + name + " connection by.",
+		Fields:      inputFields,
+	})
+	return inputObject, names, conditions
+}
+
+// Converts a camelCase field name to the SCREAMING_SNAKE_CASE used for enum values.
+// A word starts at an upper case letter following a lower case one, or at the
+// last letter of a run of capitals followed by a lower case one, so that
+// acronyms stay whole: `userID` becomes USER_ID and `URLPath` URL_PATH.
+func enumValueName(field string) string {
+	runes := []rune(field)
+	name := []rune{}
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextIsLower {
+				name = append(name, '_')
+			}
+		}
+		name = append(name, unicode.ToUpper(r))
+	}
+	return string(name)
+}
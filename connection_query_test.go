@@ -0,0 +1,232 @@
+package relay_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+)
+
+var connectionQueryTestUsers = []*user{
+	&user{ID: 1, Name: "Dan"},
+	&user{ID: 2, Name: "Nick"},
+	&user{ID: 3, Name: "Lee"},
+	&user{ID: 4, Name: "Joe"},
+	&user{ID: 5, Name: "Tim"},
+}
+
+var connectionQueryTestDef *relay.GraphQLConnectionDefinitions
+var connectionQueryTestSchema graphql.Schema
+
+// the parsed arguments of the last query
+var connectionQueryTestArgs relay.ConnectionQueryArguments
+
+func init() {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	connectionQueryTestDef = relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name:        "User",
+		NodeType:    userType,
+		OrderFields: []string{"name", "createdAt"},
+		FilterFields: []relay.FilterFieldConfig{
+			{Name: "name", Type: graphql.String, Operators: []relay.FilterOperator{relay.FilterEq, relay.FilterContains}},
+			{Name: "id", Type: graphql.Int, Operators: []relay.FilterOperator{relay.FilterGt, relay.FilterIn}},
+		},
+	})
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"users": &graphql.Field{
+				Type: connectionQueryTestDef.ConnectionType,
+				Args: connectionQueryTestDef.Args,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					args, err := connectionQueryTestDef.ParseArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					connectionQueryTestArgs = args
+
+					users := []interface{}{}
+					for _, u := range connectionQueryTestUsers {
+						keep := true
+						for _, condition := range args.Filter {
+							switch condition.Operator {
+							case relay.FilterContains:
+								keep = keep && strings.Contains(u.Name, condition.Value.(string))
+							case relay.FilterGt:
+								keep = keep && u.ID > condition.Value.(int)
+							}
+						}
+						if keep {
+							users = append(users, u)
+						}
+					}
+					for _, order := range args.OrderBy {
+						if order.Field == "name" {
+							sort.SliceStable(users, func(i, j int) bool {
+								return (users[i].(*user).Name < users[j].(*user).Name) != order.Descending
+							})
+						}
+					}
+					return relay.ConnectionFromArray(users, args.ConnectionArguments), nil
+				},
+			},
+		},
+	})
+	var err error
+	connectionQueryTestSchema, err = graphql.NewSchema(graphql.SchemaConfig{
+		Query: queryType,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestConnectionDefinition_GeneratesOrderAndFilterTypes(t *testing.T) {
+	if connectionQueryTestDef.OrderType.Name() != "UserOrder" {
+		t.Fatalf("wrong order type name, got: %v", connectionQueryTestDef.OrderType.Name())
+	}
+	values := []string{}
+	for _, value := range connectionQueryTestDef.OrderType.Values() {
+		values = append(values, value.Name)
+	}
+	sort.Strings(values)
+	expectedValues := []string{"CREATED_AT_ASC", "CREATED_AT_DESC", "NAME_ASC", "NAME_DESC"}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Fatalf("wrong order values, expected: %v, got: %v", expectedValues, values)
+	}
+
+	if connectionQueryTestDef.FilterType.Name() != "UserFilter" {
+		t.Fatalf("wrong filter type name, got: %v", connectionQueryTestDef.FilterType.Name())
+	}
+	fields := []string{}
+	for name := range connectionQueryTestDef.FilterType.Fields() {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	expectedFields := []string{"idGt", "idIn", "nameContains", "nameEq"}
+	if !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf("wrong filter fields, expected: %v, got: %v", expectedFields, fields)
+	}
+
+	for _, arg := range []string{"before", "after", "first", "last", "orderBy", "filter"} {
+		if _, ok := connectionQueryTestDef.Args[arg]; !ok {
+			t.Fatalf("missing argument %v", arg)
+		}
+	}
+}
+func TestConnectionDefinition_KeepsAcronymsWholeInOrderValues(t *testing.T) {
+	def := relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name: "Account",
+		NodeType: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Account",
+			Fields: graphql.Fields{
+				"userID": &graphql.Field{
+					Type: graphql.Int,
+				},
+			},
+		}),
+		OrderFields: []string{"userID", "URLPath", "htmlURL", "version2Name"},
+	})
+	values := []string{}
+	for _, value := range def.OrderType.Values() {
+		values = append(values, value.Name)
+	}
+	sort.Strings(values)
+	expectedValues := []string{
+		"HTML_URL_ASC", "HTML_URL_DESC",
+		"URL_PATH_ASC", "URL_PATH_DESC",
+		"USER_ID_ASC", "USER_ID_DESC",
+		"VERSION2_NAME_ASC", "VERSION2_NAME_DESC",
+	}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Fatalf("wrong order values, expected: %v, got: %v", expectedValues, values)
+	}
+
+	for _, value := range def.OrderType.Values() {
+		if value.Name != "USER_ID_DESC" {
+			continue
+		}
+		expectedOrder := relay.OrderBy{Field: "userID", Descending: true}
+		if !reflect.DeepEqual(value.Value, expectedOrder) {
+			t.Fatalf("wrong order, expected: %v, got: %v", expectedOrder, value.Value)
+		}
+	}
+}
+func TestConnectionDefinition_ParsesOrderAndFilterArguments(t *testing.T) {
+	query := `
+      query UsersQuery {
+        users(first: 2, orderBy: [NAME_DESC], filter: {idGt: 1, nameContains: "i"}) {
+          edges {
+            node {
+              name
+            }
+          }
+        }
+      }
+    `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"users": map[string]interface{}{
+				"edges": []interface{}{
+					map[string]interface{}{
+						"node": map[string]interface{}{
+							"name": "Tim",
+						},
+					},
+					map[string]interface{}{
+						"node": map[string]interface{}{
+							"name": "Nick",
+						},
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        connectionQueryTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+
+	expectedArgs := relay.ConnectionQueryArguments{
+		ConnectionArguments: relay.ConnectionArguments{
+			First: 2,
+			Last:  -1,
+		},
+		OrderBy: []relay.OrderBy{
+			{Field: "name", Descending: true},
+		},
+		Filter: []relay.FilterCondition{
+			{Field: "name", Operator: relay.FilterContains, Value: "i"},
+			{Field: "id", Operator: relay.FilterGt, Value: 1},
+		},
+	}
+	if !reflect.DeepEqual(connectionQueryTestArgs, expectedArgs) {
+		t.Fatalf("wrong arguments, arguments diff: %v", testutil.Diff(expectedArgs, connectionQueryTestArgs))
+	}
+}
+func TestConnectionDefinition_ParseArgsValidatesConnectionArguments(t *testing.T) {
+	_, err := connectionQueryTestDef.ParseArgs(map[string]interface{}{
+		"first": -1,
+	})
+	if !errors.Is(err, relay.ErrInvalidPageSize) {
+		t.Fatalf("expected ErrInvalidPageSize, got: %v", err)
+	}
+}
@@ -132,3 +132,234 @@ func TestConnectionDefinition_IncludesConnectionAndEdgeFields(t *testing.T) {
 		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
 	}
 }
+
+func TestConnectionDefinition_IncludesTotalCountAndNodesFields(t *testing.T) {
+	petType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Pet",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	petConnectionDef := relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name:       "Pet",
+		NodeType:   petType,
+		TotalCount: true,
+		Nodes:      true,
+	})
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"pets": &graphql.Field{
+				Type: petConnectionDef.ConnectionType,
+				Args: relay.ConnectionArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					arg := relay.NewConnectionArguments(p.Args)
+					// only the requested window is fetched, the total comes from the meta info
+					slice := []interface{}{
+						map[string]interface{}{"name": "Rex"},
+						map[string]interface{}{"name": "Tom"},
+					}
+					return relay.ConnectionFromArraySlice(slice, arg, relay.ArraySliceMetaInfo{
+						SliceStart:  0,
+						ArrayLength: 42,
+					}), nil
+				},
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: queryType,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := `
+      query PetsQuery {
+        pets(first: 2) {
+          totalCount
+          nodes {
+            name
+          }
+        }
+      }
+    `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"pets": map[string]interface{}{
+				"totalCount": 42,
+				"nodes": []interface{}{
+					map[string]interface{}{
+						"name": "Rex",
+					},
+					map[string]interface{}{
+						"name": "Tom",
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestConnectionDefinition_ReturnsErrorForUnknownTotalCount(t *testing.T) {
+	petType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Pet",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	petConnectionDef := relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name:       "Pet",
+		NodeType:   petType,
+		TotalCount: true,
+	})
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"pets": &graphql.Field{
+				Type: petConnectionDef.ConnectionType,
+				Args: relay.ConnectionArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					fetch := func(query relay.KeysetQuery) ([]interface{}, error) {
+						return []interface{}{map[string]interface{}{"name": "Rex"}}, nil
+					}
+					key := func(node interface{}) []interface{} {
+						return []interface{}{node.(map[string]interface{})["name"]}
+					}
+					return relay.ConnectionFromKeyset(fetch, key, relay.NewConnectionArguments(p.Args))
+				},
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: queryType,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `query { pets(first: 2) { totalCount } }`,
+	})
+	expectedData := map[string]interface{}{
+		"pets": nil,
+	}
+	if !reflect.DeepEqual(result.Data, expectedData) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expectedData, result.Data))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Message != relay.ErrTotalCountUnknown.Error() {
+		t.Fatalf("expected ErrTotalCountUnknown, got: %v", result.Errors)
+	}
+}
+
+func TestConnectionDefinition_ResolvesEdgeFieldsFromEdgeData(t *testing.T) {
+	friendType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Friend",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	friendConnectionDef := relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name:     "FriendSince",
+		NodeType: friendType,
+		EdgeFields: graphql.Fields{
+			"friendsSince": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"friends": &graphql.Field{
+				Type: friendConnectionDef.ConnectionType,
+				Args: relay.ConnectionArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					arg := relay.NewConnectionArguments(p.Args)
+					friends := []relay.NodeWithEdgeData{
+						{
+							Node:     &user{Name: "Dan"},
+							EdgeData: map[string]interface{}{"friendsSince": "2014"},
+						},
+						{
+							Node: &user{Name: "Nick"},
+							EdgeData: struct {
+								FriendsSince string `json:"friendsSince"`
+							}{"2015"},
+						},
+						{
+							Node: &user{Name: "Lee"},
+						},
+					}
+					return relay.ConnectionFromArrayWithEdgeData(friends, arg), nil
+				},
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: queryType,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := `
+      query FriendsQuery {
+        friends(first: 3) {
+          edges {
+            friendsSince
+            node {
+              name
+            }
+          }
+        }
+      }
+    `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"friends": map[string]interface{}{
+				"edges": []interface{}{
+					map[string]interface{}{
+						"friendsSince": "2014",
+						"node": map[string]interface{}{
+							"name": "Dan",
+						},
+					},
+					map[string]interface{}{
+						"friendsSince": "2015",
+						"node": map[string]interface{}{
+							"name": "Nick",
+						},
+					},
+					map[string]interface{}{
+						"friendsSince": nil,
+						"node": map[string]interface{}{
+							"name": "Lee",
+						},
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
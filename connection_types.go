@@ -16,6 +16,13 @@ type PageInfo struct {
 type Connection struct {
 	Edges    []*Edge  `json:"edges"`
 	PageInfo PageInfo `json:"pageInfo"`
+
+	// The number of items in the whole connection, not only in this page;
+	// nil when the helper building the connection cannot count them.
+	TotalCount *int `json:"totalCount"`
+
+	// Set when ConnectionOptions.PageCursors is.
+	PageCursors *PageCursors `json:"pageCursors"`
 }
 
 func NewConnection() *Connection {
@@ -28,6 +35,11 @@ func NewConnection() *Connection {
 type Edge struct {
 	Node   interface{}      `json:"node"`
 	Cursor ConnectionCursor `json:"cursor"`
+
+	// Data about the relationship rather than the node, such as the date two
+	// users became friends. Edge fields without a resolve function are
+	// resolved from it.
+	EdgeData interface{} `json:"edgeData"`
 }
 
 // Use NewConnectionArguments() to properly initialize default values
@@ -47,7 +59,12 @@ type ConnectionArgumentsConfig struct {
 	Last  *int `json:"last"`
 }
 
-func NewConnectionArguments(filters map[string]interface{}) ConnectionArguments {
+/*
+Creates connection arguments from a map of field arguments, ignoring
+arguments that are not valid; use NewValidatedConnectionArguments to report
+them instead. Page sizes over the maximum of a PaginationPolicy are clamped.
+*/
+func NewConnectionArguments(filters map[string]interface{}, opts ...ConnectionOptions) ConnectionArguments {
 	conn := ConnectionArguments{
 		First:  -1,
 		Last:   -1,
@@ -72,5 +89,5 @@ func NewConnectionArguments(filters map[string]interface{}) ConnectionArguments
 			conn.After = ConnectionCursor(fmt.Sprintf("%v", after))
 		}
 	}
-	return conn
+	return mergeConnectionOptions(opts).PaginationPolicy.clamp(conn)
 }
@@ -0,0 +1,172 @@
+package relay
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Translates between array offsets and the opaque cursors handed to clients.
+
+Implementations can version cursors, change their encoding, or carry extra
+state in them; `DecodeCursor` must accept every cursor `EncodeCursor` returns.
+*/
+type CursorCodec interface {
+	EncodeCursor(offset int) ConnectionCursor
+	DecodeCursor(cursor ConnectionCursor) (int, error)
+}
+
+/*
+A CursorCodec that base64-encodes the offset behind a prefix.
+
+The zero value produces the package's default cursors, `arrayconnection:<offset>`
+in standard base64. Set `Prefix` to version cursors, and `Encoding` to use
+e.g. `base64.RawURLEncoding`.
+*/
+type Base64CursorCodec struct {
+	Prefix   string
+	Encoding *base64.Encoding
+}
+
+/*
+Implemented by cursor codecs that can also protect cursors that are not
+offsets, such as the sort keys of keyset cursors or the positions of merged
+cursors. `DecodePayload` must accept every cursor `EncodePayload` returns.
+
+The helpers building such cursors return an error when given a CursorCodec
+that does not implement this interface, rather than handing out cursors the
+codec did not protect.
+*/
+type PayloadCursorCodec interface {
+	EncodePayload(payload []byte) ConnectionCursor
+	DecodePayload(cursor ConnectionCursor) ([]byte, error)
+}
+
+// The codec used when no CursorCodec is given in ConnectionOptions.
+var DefaultCursorCodec CursorCodec = Base64CursorCodec{}
+
+func (c Base64CursorCodec) EncodeCursor(offset int) ConnectionCursor {
+	str := fmt.Sprintf("%v%v", c.prefix(), offset)
+	return ConnectionCursor(c.encoding().EncodeToString([]byte(str)))
+}
+
+func (c Base64CursorCodec) DecodeCursor(cursor ConnectionCursor) (int, error) {
+	str := ""
+	b, err := c.encoding().DecodeString(string(cursor))
+	if err == nil {
+		str = string(b)
+	}
+	str = strings.Replace(str, c.prefix(), "", -1)
+	offset, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, errors.New("Invalid cursor")
+	}
+	return offset, nil
+}
+
+func (c Base64CursorCodec) prefix() string {
+	if c.Prefix == "" {
+		return PREFIX
+	}
+	return c.Prefix
+}
+
+func (c Base64CursorCodec) encoding() *base64.Encoding {
+	if c.Encoding == nil {
+		return base64.StdEncoding
+	}
+	return c.Encoding
+}
+
+/*
+Optional settings accepted by the connection helpers.
+
+The helpers take options as a trailing variadic argument so existing callers
+keep working; when several are given, later non-zero fields win.
+*/
+type ConnectionOptions struct {
+	CursorCodec CursorCodec
+
+	// Makes NewValidatedConnectionArguments reject `first` and `last` given
+	// together, which the Relay specification strongly discourages.
+	RejectFirstAndLast bool
+
+	// Default and maximum page sizes, applied by NewConnectionArguments,
+	// NewValidatedConnectionArguments and the ConnectionFrom* helpers.
+	PaginationPolicy *PaginationPolicy
+
+	// Makes the ConnectionFrom* helpers compute both `hasPreviousPage` and
+	// `hasNextPage` whatever the direction of pagination, instead of
+	// reporting false for the direction that was not requested.
+	BidirectionalPageInfo bool
+
+	// Makes the ConnectionFrom*Slice helpers fill `PageCursors`, using
+	// `first` or `last` as the page size.
+	PageCursors bool
+}
+
+func mergeConnectionOptions(opts []ConnectionOptions) ConnectionOptions {
+	merged := ConnectionOptions{}
+	for _, opt := range opts {
+		if opt.CursorCodec != nil {
+			merged.CursorCodec = opt.CursorCodec
+		}
+		if opt.RejectFirstAndLast {
+			merged.RejectFirstAndLast = true
+		}
+		if opt.PaginationPolicy != nil {
+			merged.PaginationPolicy = opt.PaginationPolicy
+		}
+		if opt.BidirectionalPageInfo {
+			merged.BidirectionalPageInfo = true
+		}
+		if opt.PageCursors {
+			merged.PageCursors = true
+		}
+	}
+	return merged
+}
+
+func (o ConnectionOptions) cursorCodec() CursorCodec {
+	if o.CursorCodec == nil {
+		return DefaultCursorCodec
+	}
+	return o.CursorCodec
+}
+
+/*
+Returns the codec protecting the cursors that are not offsets, or nil if they
+are left as they are, which is the case without a CursorCodec or with a
+Base64CursorCodec.
+*/
+func (o ConnectionOptions) payloadCodec() (PayloadCursorCodec, error) {
+	switch codec := o.CursorCodec.(type) {
+	case nil, Base64CursorCodec, *Base64CursorCodec:
+		return nil, nil
+	case PayloadCursorCodec:
+		return codec, nil
+	default:
+		return nil, fmt.Errorf("Cursor codec %T can only encode offsets", codec)
+	}
+}
+
+func encodePayloadCursor(codec PayloadCursorCodec, cursor ConnectionCursor) ConnectionCursor {
+	if codec == nil {
+		return cursor
+	}
+	return codec.EncodePayload([]byte(cursor))
+}
+
+func decodePayloadCursor(codec PayloadCursorCodec, cursor ConnectionCursor) (ConnectionCursor, error) {
+	if codec == nil {
+		return cursor, nil
+	}
+	payload, err := codec.DecodePayload(cursor)
+	if err != nil {
+		return "", err
+	}
+	return ConnectionCursor(payload), nil
+}
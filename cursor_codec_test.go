@@ -0,0 +1,89 @@
+package relay_test
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+)
+
+func TestDefaultCursorCodec_MatchesOffsetToCursor(t *testing.T) {
+	for offset := 0; offset < 5; offset++ {
+		cursor := relay.DefaultCursorCodec.EncodeCursor(offset)
+		if cursor != relay.OffsetToCursor(offset) {
+			t.Fatalf("wrong cursor, expected: %v, got: %v", relay.OffsetToCursor(offset), cursor)
+		}
+		decoded, err := relay.DefaultCursorCodec.DecodeCursor(cursor)
+		if err != nil || decoded != offset {
+			t.Fatalf("wrong offset, expected: %v, got: %v, %v", offset, decoded, err)
+		}
+	}
+}
+func TestBase64CursorCodec_UsesPrefixAndEncoding(t *testing.T) {
+	codec := relay.Base64CursorCodec{
+		Prefix:   "v2:",
+		Encoding: base64.RawURLEncoding,
+	}
+	cursor := codec.EncodeCursor(3)
+	if cursor != "djI6Mw" {
+		t.Fatalf("wrong cursor, got: %v", cursor)
+	}
+	offset, err := codec.DecodeCursor(cursor)
+	if err != nil || offset != 3 {
+		t.Fatalf("wrong offset, got: %v, %v", offset, err)
+	}
+	if _, err := codec.DecodeCursor(relay.OffsetToCursor(3)); err == nil {
+		t.Fatalf("expected error for cursor in the default format")
+	}
+}
+func TestConnectionFromArray_UsesCursorCodecFromOptions(t *testing.T) {
+	codec := relay.Base64CursorCodec{
+		Prefix:   "v2:",
+		Encoding: base64.RawURLEncoding,
+	}
+	options := relay.ConnectionOptions{
+		CursorCodec: codec,
+	}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(codec.EncodeCursor(1)),
+	})
+
+	expected := &relay.Connection{
+		Edges: []*relay.Edge{
+			&relay.Edge{
+				Node:   "C",
+				Cursor: codec.EncodeCursor(2),
+			},
+			&relay.Edge{
+				Node:   "D",
+				Cursor: codec.EncodeCursor(3),
+			},
+		},
+		PageInfo: relay.PageInfo{
+			StartCursor:     codec.EncodeCursor(2),
+			EndCursor:       codec.EncodeCursor(3),
+			HasPreviousPage: false,
+			HasNextPage:     true,
+		},
+		TotalCount: arrayConnectionTestTotalCount(5),
+	}
+
+	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args, options)
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, connection result diff: %v", testutil.Diff(expected, result))
+	}
+
+	cursor := relay.CursorForObjectInConnection(arrayConnectionTestLetters, "B", options)
+	if cursor != codec.EncodeCursor(1) {
+		t.Fatalf("wrong cursor, got: %v", cursor)
+	}
+	if offset := relay.GetOffsetWithDefault(cursor, -1, options); offset != 1 {
+		t.Fatalf("wrong offset, got: %v", offset)
+	}
+	if offset := relay.GetOffsetWithDefault(cursor, -1); offset != -1 {
+		t.Fatalf("expected default offset without codec, got: %v", offset)
+	}
+}
@@ -0,0 +1,182 @@
+package relay
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Returned when an encrypted cursor or global ID cannot be decrypted.
+type DecryptionError struct {
+	Reason string
+}
+
+func (e *DecryptionError) Error() string {
+	return fmt.Sprintf("Unable to decrypt value: %v", e.Reason)
+}
+
+/*
+A set of AES-GCM keys used to encrypt and authenticate opaque values.
+
+Values are encrypted with the first key. Each value records the ID of its key,
+so values encrypted with any key of the ring can be decrypted; put a new key
+first to rotate, and drop old keys once their values have expired.
+*/
+type KeyRing struct {
+	keys  []Key
+	aeads map[string]cipher.AEAD
+}
+
+// Returns a KeyRing for the given keys. Secrets must be 16, 24 or 32 bytes
+// long to select AES-128, AES-192 or AES-256.
+func NewKeyRing(keys ...Key) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("At least one encryption key is required")
+	}
+	ring := &KeyRing{
+		keys:  keys,
+		aeads: map[string]cipher.AEAD{},
+	}
+	for _, key := range keys {
+		if key.ID == "" || len(key.ID) > 255 {
+			return nil, fmt.Errorf("Invalid encryption key ID: %q", key.ID)
+		}
+		if _, ok := ring.aeads[key.ID]; ok {
+			return nil, fmt.Errorf("Duplicate encryption key ID: %q", key.ID)
+		}
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid secret for encryption key %q: %v", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		ring.aeads[key.ID] = aead
+	}
+	return ring, nil
+}
+
+// Encrypts the plaintext with the first key of the ring, and returns it as
+// unpadded URL-safe base64.
+func (r *KeyRing) Seal(plaintext []byte) string {
+	keyID := r.keys[0].ID
+	aead := r.aeads[keyID]
+
+	header := append([]byte{byte(len(keyID))}, keyID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		panic(err)
+	}
+	sealed := aead.Seal(append(header, nonce...), nonce, plaintext, header)
+	return base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// Decrypts a value returned by Seal.
+func (r *KeyRing) Open(value string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return nil, &DecryptionError{Reason: "invalid encoding"}
+	}
+	headerLength := 1 + int(b[0])
+	if len(b) < headerLength {
+		return nil, &DecryptionError{Reason: "truncated value"}
+	}
+	header := b[:headerLength]
+	keyID := string(header[1:])
+	aead, ok := r.aeads[keyID]
+	if !ok {
+		return nil, &DecryptionError{Reason: fmt.Sprintf("unknown key %q", keyID)}
+	}
+	if len(b) < headerLength+aead.NonceSize() {
+		return nil, &DecryptionError{Reason: "truncated value"}
+	}
+	nonce := b[headerLength : headerLength+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, b[headerLength+aead.NonceSize():], header)
+	if err != nil {
+		return nil, &DecryptionError{Reason: "authentication failed"}
+	}
+	return plaintext, nil
+}
+
+/*
+A CursorCodec that encrypts the cursors of another codec, so that clients
+can neither read nor forge the offsets they contain.
+*/
+type EncryptedCursorCodec struct {
+	codec CursorCodec
+	keys  *KeyRing
+}
+
+/*
+Returns an EncryptedCursorCodec that encrypts the cursors produced by `codec`,
+or by the DefaultCursorCodec if `codec` is nil.
+*/
+func NewEncryptedCursorCodec(codec CursorCodec, keys *KeyRing) *EncryptedCursorCodec {
+	if codec == nil {
+		codec = DefaultCursorCodec
+	}
+	return &EncryptedCursorCodec{
+		codec: codec,
+		keys:  keys,
+	}
+}
+
+func (c *EncryptedCursorCodec) EncodeCursor(offset int) ConnectionCursor {
+	return ConnectionCursor(c.keys.Seal([]byte(c.codec.EncodeCursor(offset))))
+}
+
+func (c *EncryptedCursorCodec) DecodeCursor(cursor ConnectionCursor) (int, error) {
+	payload, err := c.keys.Open(string(cursor))
+	if err != nil {
+		return 0, err
+	}
+	return c.codec.DecodeCursor(ConnectionCursor(payload))
+}
+
+// Encrypts an arbitrary payload, such as the sort keys of a keyset cursor.
+func (c *EncryptedCursorCodec) EncodePayload(payload []byte) ConnectionCursor {
+	return ConnectionCursor(c.keys.Seal(payload))
+}
+
+func (c *EncryptedCursorCodec) DecodePayload(cursor ConnectionCursor) ([]byte, error) {
+	return c.keys.Open(string(cursor))
+}
+
+/*
+A GlobalIDCodec that encrypts the type name and ID, so that global IDs do not
+reveal internal identifiers such as database primary keys.
+*/
+type EncryptedGlobalIDCodec struct {
+	keys *KeyRing
+}
+
+func NewEncryptedGlobalIDCodec(keys *KeyRing) *EncryptedGlobalIDCodec {
+	return &EncryptedGlobalIDCodec{
+		keys: keys,
+	}
+}
+
+func (c *EncryptedGlobalIDCodec) ToGlobalID(ttype string, id string) string {
+	return c.keys.Seal([]byte(ttype + ":" + id))
+}
+
+func (c *EncryptedGlobalIDCodec) FromGlobalID(globalID string) (*ResolvedGlobalID, error) {
+	b, err := c.keys.Open(globalID)
+	if err != nil {
+		return nil, err
+	}
+	tokens := strings.SplitN(string(b), ":", 2)
+	if len(tokens) < 2 {
+		return nil, &DecryptionError{Reason: "missing type name"}
+	}
+	return &ResolvedGlobalID{
+		Type: tokens[0],
+		ID:   tokens[1],
+	}, nil
+}
@@ -0,0 +1,123 @@
+package relay_test
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/relay"
+)
+
+var encryptionTestOldKey = relay.Key{ID: "2015", Secret: []byte("0123456789abcdef")}
+var encryptionTestNewKey = relay.Key{ID: "2016", Secret: []byte("0123456789abcdef0123456789abcdef")}
+
+func encryptionTestKeyRing(t *testing.T, keys ...relay.Key) *relay.KeyRing {
+	ring, err := relay.NewKeyRing(keys...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return ring
+}
+
+func TestKeyRing_DecryptsValuesOfRotatedKeys(t *testing.T) {
+	oldRing := encryptionTestKeyRing(t, encryptionTestOldKey)
+	ring := encryptionTestKeyRing(t, encryptionTestNewKey, encryptionTestOldKey)
+
+	for _, value := range []string{oldRing.Seal([]byte("secret")), ring.Seal([]byte("secret"))} {
+		plaintext, err := ring.Open(value)
+		if err != nil || string(plaintext) != "secret" {
+			t.Fatalf("wrong plaintext, got: %q, %v", plaintext, err)
+		}
+	}
+	if _, err := oldRing.Open(ring.Seal([]byte("secret"))); err == nil {
+		t.Fatalf("expected error for value encrypted with unknown key")
+	}
+}
+func TestKeyRing_RejectsTamperedValues(t *testing.T) {
+	ring := encryptionTestKeyRing(t, encryptionTestNewKey)
+	b, _ := base64.RawURLEncoding.DecodeString(ring.Seal([]byte("secret")))
+	b[len(b)-1] ^= 1
+
+	values := []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString(b),
+		base64.RawURLEncoding.EncodeToString(b[:8]),
+	}
+	for _, value := range values {
+		_, err := ring.Open(value)
+		if _, ok := err.(*relay.DecryptionError); !ok {
+			t.Fatalf("expected DecryptionError for %q, got: %v", value, err)
+		}
+	}
+}
+func TestNewKeyRing_RejectsInvalidKeys(t *testing.T) {
+	keySets := [][]relay.Key{
+		{},
+		{relay.Key{ID: "short", Secret: []byte("too short")}},
+		{relay.Key{ID: "", Secret: []byte("0123456789abcdef")}},
+		{encryptionTestOldKey, encryptionTestOldKey},
+	}
+	for _, keys := range keySets {
+		if _, err := relay.NewKeyRing(keys...); err == nil {
+			t.Fatalf("expected error for keys %v", keys)
+		}
+	}
+}
+func TestEncryptedCursorCodec_HidesOffsets(t *testing.T) {
+	codec := relay.NewEncryptedCursorCodec(nil, encryptionTestKeyRing(t, encryptionTestNewKey))
+
+	cursor := codec.EncodeCursor(4)
+	if b, err := base64.StdEncoding.DecodeString(string(cursor)); err == nil && strings.Contains(string(b), relay.PREFIX) {
+		t.Fatalf("expected cursor to be encrypted, got: %v", string(b))
+	}
+	offset, err := codec.DecodeCursor(cursor)
+	if err != nil || offset != 4 {
+		t.Fatalf("wrong offset, got: %v, %v", offset, err)
+	}
+	if _, err := codec.DecodeCursor(relay.OffsetToCursor(4)); err == nil {
+		t.Fatalf("expected error for plain cursor")
+	}
+}
+func TestEncryptedGlobalIDCodec_RoundTripsGlobalIDs(t *testing.T) {
+	codec := relay.NewEncryptedGlobalIDCodec(encryptionTestKeyRing(t, encryptionTestNewKey))
+
+	globalID := codec.ToGlobalID("User", "urn:user:1")
+	if strings.Contains(globalID, relay.ToGlobalID("User", "urn:user:1")) {
+		t.Fatalf("expected global ID to be encrypted, got: %v", globalID)
+	}
+	resolved, err := codec.FromGlobalID(globalID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &relay.ResolvedGlobalID{
+		Type: "User",
+		ID:   "urn:user:1",
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Fatalf("wrong result, expected: %v, got: %v", expected, resolved)
+	}
+	if _, err := codec.FromGlobalID(relay.ToGlobalID("User", "1")); err == nil {
+		t.Fatalf("expected error for unencrypted global ID")
+	}
+}
+func TestGlobalIDFieldWithCodec_UsesCodec(t *testing.T) {
+	codec := relay.NewEncryptedGlobalIDCodec(encryptionTestKeyRing(t, encryptionTestNewKey))
+	field := relay.GlobalIDFieldWithCodec("User", nil, codec)
+
+	globalID, err := field.Resolve(graphql.ResolveParams{
+		Source: &user{ID: 1, Name: "John Doe"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resolved, err := codec.FromGlobalID(globalID.(string))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Type != "User" || resolved.ID != "1" {
+		t.Fatalf("wrong result, got: %v", resolved)
+	}
+}
@@ -0,0 +1,115 @@
+package relay
+
+type TypedEdge[T any] struct {
+	Node   T                `json:"node"`
+	Cursor ConnectionCursor `json:"cursor"`
+}
+
+type TypedConnection[T any] struct {
+	Edges       []*TypedEdge[T] `json:"edges"`
+	PageInfo    PageInfo        `json:"pageInfo"`
+	TotalCount  *int            `json:"totalCount"`
+	PageCursors *PageCursors    `json:"pageCursors"`
+}
+
+func NewTypedConnection[T any]() *TypedConnection[T] {
+	return &TypedConnection[T]{
+		Edges:    []*TypedEdge[T]{},
+		PageInfo: PageInfo{},
+	}
+}
+
+/*
+The type-safe counterpart of `ConnectionFromArray`: accepts a slice of any
+element type and connection arguments, and returns a connection whose edges
+keep the element type of the slice.
+*/
+func ConnectionFromSlice[T any](data []T, args ConnectionArguments, opts ...ConnectionOptions) *TypedConnection[T] {
+	return ConnectionFromSliceWindow(
+		data,
+		args,
+		ArraySliceMetaInfo{
+			SliceStart:  0,
+			ArrayLength: len(data),
+		},
+		opts...,
+	)
+}
+
+/*
+The type-safe counterpart of `ConnectionFromArraySlice`: given a window of a
+larger array, described by `meta`, returns a typed connection object.
+*/
+func ConnectionFromSliceWindow[T any](
+	slice []T,
+	args ConnectionArguments,
+	meta ArraySliceMetaInfo,
+	opts ...ConnectionOptions,
+) *TypedConnection[T] {
+	options := mergeConnectionOptions(opts)
+	codec := options.cursorCodec()
+	args = options.PaginationPolicy.clamp(args)
+	window := newArraySliceWindow(len(slice), args, meta, options)
+	if window.empty() {
+		conn := NewTypedConnection[T]()
+		conn.TotalCount = &meta.ArrayLength
+		if options.PageCursors {
+			conn.PageCursors = window.pageCursors(args, meta, options)
+		}
+		return conn
+	}
+
+	edges := []*TypedEdge[T]{}
+	for index, value := range slice[window.begin:window.end] {
+		edges = append(edges, &TypedEdge[T]{
+			Cursor: codec.EncodeCursor(window.startOffset + index),
+			Node:   value,
+		})
+	}
+
+	var firstEdgeCursor, lastEdgeCursor ConnectionCursor
+	if len(edges) > 0 {
+		firstEdgeCursor = edges[0].Cursor
+		lastEdgeCursor = edges[len(edges)-1].Cursor
+	}
+
+	conn := NewTypedConnection[T]()
+	conn.Edges = edges
+	conn.PageInfo = window.pageInfo(firstEdgeCursor, lastEdgeCursor)
+	conn.TotalCount = &meta.ArrayLength
+	if options.PageCursors {
+		conn.PageCursors = window.pageCursors(args, meta, options)
+	}
+
+	return conn
+}
+
+// Returns the nodes of the connection, in edge order.
+func (c *TypedConnection[T]) Nodes() []T {
+	nodes := make([]T, 0, len(c.Edges))
+	for _, edge := range c.Edges {
+		nodes = append(nodes, edge.Node)
+	}
+	return nodes
+}
+
+// Converts the typed connection into the untyped `*Connection` that
+// graphql-go resolvers return.
+func (c *TypedConnection[T]) ToConnection() *Connection {
+	conn := NewConnection()
+	for _, edge := range c.Edges {
+		conn.Edges = append(conn.Edges, edge.ToEdge())
+	}
+	conn.PageInfo = c.PageInfo
+	conn.TotalCount = c.TotalCount
+	conn.PageCursors = c.PageCursors
+	return conn
+}
+
+// Converts the typed edge into an untyped `*Edge`.
+func (e *TypedEdge[T]) ToEdge() *Edge {
+	return &Edge{
+		Node:   e.Node,
+		Cursor: e.Cursor,
+	}
+}
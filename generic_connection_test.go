@@ -0,0 +1,107 @@
+package relay_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+)
+
+var genericConnectionTestLetters = []string{
+	"A", "B", "C", "D", "E",
+}
+
+func TestConnectionFromSlice_RespectsFirstAndAfter(t *testing.T) {
+	filter := map[string]interface{}{
+		"first": 2,
+		"after": "YXJyYXljb25uZWN0aW9uOjE=",
+	}
+	args := relay.NewConnectionArguments(filter)
+
+	expected := &relay.TypedConnection[string]{
+		Edges: []*relay.TypedEdge[string]{
+			&relay.TypedEdge[string]{
+				Node:   "C",
+				Cursor: "YXJyYXljb25uZWN0aW9uOjI=",
+			},
+			&relay.TypedEdge[string]{
+				Node:   "D",
+				Cursor: "YXJyYXljb25uZWN0aW9uOjM=",
+			},
+		},
+		PageInfo: relay.PageInfo{
+			StartCursor:     "YXJyYXljb25uZWN0aW9uOjI=",
+			EndCursor:       "YXJyYXljb25uZWN0aW9uOjM=",
+			HasPreviousPage: false,
+			HasNextPage:     true,
+		},
+		TotalCount: arrayConnectionTestTotalCount(5),
+	}
+
+	result := relay.ConnectionFromSlice(genericConnectionTestLetters, args)
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, connection result diff: %v", testutil.Diff(expected, result))
+	}
+	if nodes := result.Nodes(); !reflect.DeepEqual(nodes, []string{"C", "D"}) {
+		t.Fatalf("wrong nodes, got: %v", nodes)
+	}
+}
+func TestConnectionFromSlice_MatchesConnectionFromArray(t *testing.T) {
+	filters := []map[string]interface{}{
+		nil,
+		{"first": 2},
+		{"last": 2},
+		{"first": 2, "after": "YXJyYXljb25uZWN0aW9uOjE="},
+		{"last": 2, "before": "YXJyYXljb25uZWN0aW9uOjM="},
+		{"first": 0},
+		{"after": "YXJyYXljb25uZWN0aW9uOjE=", "before": "YXJyYXljb25uZWN0aW9uOjI="},
+	}
+	untypedLetters := []interface{}{}
+	for _, letter := range genericConnectionTestLetters {
+		untypedLetters = append(untypedLetters, letter)
+	}
+	for _, filter := range filters {
+		args := relay.NewConnectionArguments(filter)
+		expected := relay.ConnectionFromArray(untypedLetters, args)
+		result := relay.ConnectionFromSlice(genericConnectionTestLetters, args).ToConnection()
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("wrong result for %v, connection result diff: %v", filter, testutil.Diff(expected, result))
+		}
+	}
+}
+func TestConnectionFromSliceWindow_UndersizedSliceBoth(t *testing.T) {
+	filter := map[string]interface{}{
+		"first": 3,
+		"after": "YXJyYXljb25uZWN0aW9uOjA=",
+	}
+	args := relay.NewConnectionArguments(filter)
+
+	expected := &relay.TypedConnection[string]{
+		Edges: []*relay.TypedEdge[string]{
+			&relay.TypedEdge[string]{
+				Node:   "D",
+				Cursor: "YXJyYXljb25uZWN0aW9uOjM=",
+			},
+		},
+		PageInfo: relay.PageInfo{
+			StartCursor:     "YXJyYXljb25uZWN0aW9uOjM=",
+			EndCursor:       "YXJyYXljb25uZWN0aW9uOjM=",
+			HasPreviousPage: false,
+			HasNextPage:     true,
+		},
+		TotalCount: arrayConnectionTestTotalCount(5),
+	}
+
+	result := relay.ConnectionFromSliceWindow(
+		genericConnectionTestLetters[3:4],
+		args,
+		relay.ArraySliceMetaInfo{
+			SliceStart:  3,
+			ArrayLength: 5,
+		},
+	)
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, connection result diff: %v", testutil.Diff(expected, result))
+	}
+}
@@ -0,0 +1,209 @@
+package relay
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+var (
+	// The global ID is not valid base64.
+	ErrInvalidGlobalIDEncoding = errors.New("Invalid global ID encoding")
+	// The decoded global ID does not start with a type name followed by a colon.
+	ErrMissingGlobalIDSeparator = errors.New("Missing global ID type separator")
+	// The global ID is not of one of the expected types; see GlobalIDTypeError.
+	ErrUnexpectedGlobalIDType = errors.New("Unexpected global ID type")
+)
+
+/*
+Like FromGlobalID, but returns ErrInvalidGlobalIDEncoding or
+ErrMissingGlobalIDSeparator instead of nil for invalid global IDs. If expected
+types are given, returns a *GlobalIDTypeError, matching
+ErrUnexpectedGlobalIDType, for global IDs of other types.
+*/
+func DecodeGlobalID(globalID string, expectedTypes ...string) (ResolvedGlobalID, error) {
+	b, err := base64.StdEncoding.DecodeString(globalID)
+	if err != nil {
+		return ResolvedGlobalID{}, ErrInvalidGlobalIDEncoding
+	}
+	tokens := strings.SplitN(string(b), ":", 2)
+	if len(tokens) < 2 || tokens[0] == "" {
+		return ResolvedGlobalID{}, ErrMissingGlobalIDSeparator
+	}
+	resolvedID := ResolvedGlobalID{
+		Type: tokens[0],
+		ID:   tokens[1],
+	}
+	if len(expectedTypes) == 0 {
+		return resolvedID, nil
+	}
+	for _, ttype := range expectedTypes {
+		if resolvedID.Type == ttype {
+			return resolvedID, nil
+		}
+	}
+	return ResolvedGlobalID{}, &GlobalIDTypeError{GlobalID: globalID, Type: resolvedID.Type, ExpectedTypes: expectedTypes}
+}
+
+/*
+Returns an `ID`-like scalar type that only accepts global IDs of the expected
+types, or any valid global ID if none are given, so invalid IDs are rejected
+during argument coercion. Arguments and input fields of the type are given to
+resolvers as ResolvedGlobalID values.
+*/
+func NewGlobalIDScalar(name string, expectedTypes ...string) *graphql.Scalar {
+	parseValue := func(value interface{}) interface{} {
+		globalID, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		resolvedID, err := DecodeGlobalID(globalID, expectedTypes...)
+		if err != nil {
+			return nil
+		}
+		return resolvedID
+	}
+	description := "A global ID"
+	if len(expectedTypes) > 0 {
+		description += " of type " + strings.Join(expectedTypes, " or ")
+	}
+	return graphql.NewScalar(graphql.ScalarConfig{
+		Name:        name,
+		Description: description + ".",
+		Serialize: func(value interface{}) interface{} {
+			switch value := value.(type) {
+			case ResolvedGlobalID:
+				return ToGlobalID(value.Type, value.ID)
+			case *ResolvedGlobalID:
+				return ToGlobalID(value.Type, value.ID)
+			}
+			return graphql.ID.Serialize(value)
+		},
+		ParseValue: parseValue,
+		ParseLiteral: func(valueAST ast.Value) interface{} {
+			if value, ok := valueAST.(*ast.StringValue); ok {
+				return parseValue(value.Value)
+			}
+			return nil
+		},
+	})
+}
+
+// Returned when a global ID is not of one of the expected types.
+type GlobalIDTypeError struct {
+	GlobalID      string
+	Type          string
+	ExpectedTypes []string
+}
+
+func (e *GlobalIDTypeError) Error() string {
+	return fmt.Sprintf("Global ID of type %v, expected %v", e.Type, strings.Join(e.ExpectedTypes, " or "))
+}
+
+func (e *GlobalIDTypeError) Unwrap() error {
+	return ErrUnexpectedGlobalIDType
+}
+
+// Returned when a part of the ID of a global ID cannot be parsed.
+type GlobalIDPartError struct {
+	GlobalID string
+	Part     int
+	Value    string
+	Err      error
+}
+
+func (e *GlobalIDPartError) Error() string {
+	return fmt.Sprintf("Invalid part %v of global ID: %v", e.Part, e.Err)
+}
+
+func (e *GlobalIDPartError) Unwrap() error {
+	return e.Err
+}
+
+/*
+Returns a global ID whose ID is made of several parts, e.g. the columns of a
+compound primary key. The parts are escaped, so they can contain any
+character, colons included.
+
+At least one part is required: the ID of no parts would be decoded as a
+single empty part, so ToGlobalIDParts panics without parts.
+*/
+func ToGlobalIDParts(ttype string, parts ...string) string {
+	if len(parts) == 0 {
+		panic("relay: ToGlobalIDParts requires at least one part")
+	}
+	escaped := []string{}
+	for _, part := range parts {
+		escaped = append(escaped, url.QueryEscape(part))
+	}
+	return ToGlobalID(ttype, strings.Join(escaped, ":"))
+}
+
+/*
+Takes a global ID created by ToGlobalIDParts, and returns its type name and
+parts. Returns the errors of DecodeGlobalID, and a *GlobalIDPartError for
+parts that are not escaped.
+*/
+func FromGlobalIDParts(globalID string, expectedTypes ...string) (string, []string, error) {
+	resolvedID, err := DecodeGlobalID(globalID, expectedTypes...)
+	if err != nil {
+		return "", nil, err
+	}
+	parts := []string{}
+	for i, part := range strings.Split(resolvedID.ID, ":") {
+		unescaped, err := url.QueryUnescape(part)
+		if err != nil {
+			return "", nil, &GlobalIDPartError{GlobalID: globalID, Part: i, Value: part, Err: err}
+		}
+		parts = append(parts, unescaped)
+	}
+	return resolvedID.Type, parts, nil
+}
+
+/*
+Takes a global ID whose ID is an integer, and returns the integer. Returns the
+errors of DecodeGlobalID, and a *GlobalIDPartError for IDs that are not
+integers.
+*/
+func FromGlobalIDInt64(globalID string, expectedTypes ...string) (int64, error) {
+	resolvedID, err := DecodeGlobalID(globalID, expectedTypes...)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(resolvedID.ID, 10, 64)
+	if err != nil {
+		return 0, &GlobalIDPartError{GlobalID: globalID, Value: resolvedID.ID, Err: err}
+	}
+	return id, nil
+}
+
+/*
+Takes a global ID whose ID is a UUID in its canonical form, and returns the
+UUID bytes, which convert to the UUID types of the common packages. Returns
+the errors of DecodeGlobalID, and a *GlobalIDPartError for IDs that are not
+UUIDs.
+*/
+func FromGlobalIDUUID(globalID string, expectedTypes ...string) ([16]byte, error) {
+	var uuid [16]byte
+	resolvedID, err := DecodeGlobalID(globalID, expectedTypes...)
+	if err != nil {
+		return uuid, err
+	}
+	id := resolvedID.ID
+	if len(id) != 36 || id[8] != '-' || id[13] != '-' || id[18] != '-' || id[23] != '-' {
+		return uuid, &GlobalIDPartError{GlobalID: globalID, Value: id, Err: errors.New("not a UUID")}
+	}
+	b, err := hex.DecodeString(id[0:8] + id[9:13] + id[14:18] + id[19:23] + id[24:])
+	if err != nil {
+		return uuid, &GlobalIDPartError{GlobalID: globalID, Value: id, Err: err}
+	}
+	copy(uuid[:], b)
+	return uuid, nil
+}
@@ -0,0 +1,221 @@
+package relay_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+func TestToGlobalIDParts_RoundTripsParts(t *testing.T) {
+	parts := []string{"acme:corp", "2015", "a b%c", ""}
+	globalID := relay.ToGlobalIDParts("Invoice", parts...)
+
+	ttype, decoded, err := relay.FromGlobalIDParts(globalID, "Invoice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ttype != "Invoice" || !reflect.DeepEqual(decoded, parts) {
+		t.Fatalf("wrong parts, expected: %v, got: %v, %v", parts, ttype, decoded)
+	}
+}
+func TestToGlobalIDParts_RoundTripsASingleEmptyPart(t *testing.T) {
+	ttype, decoded, err := relay.FromGlobalIDParts(relay.ToGlobalIDParts("Invoice", ""), "Invoice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ttype != "Invoice" || !reflect.DeepEqual(decoded, []string{""}) {
+		t.Fatalf("wrong parts, expected: [\"\"], got: %v, %q", ttype, decoded)
+	}
+}
+func TestToGlobalIDParts_RejectsZeroParts(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected a panic for zero parts")
+		}
+	}()
+	relay.ToGlobalIDParts("Invoice")
+}
+func TestFromGlobalIDParts_ChecksTheType(t *testing.T) {
+	_, _, err := relay.FromGlobalIDParts(relay.ToGlobalIDParts("Invoice", "1", "2"), "Order", "Receipt")
+	var typeErr *relay.GlobalIDTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected *GlobalIDTypeError, got: %v", err)
+	}
+	expected := &relay.GlobalIDTypeError{
+		GlobalID:      relay.ToGlobalIDParts("Invoice", "1", "2"),
+		Type:          "Invoice",
+		ExpectedTypes: []string{"Order", "Receipt"},
+	}
+	if !reflect.DeepEqual(typeErr, expected) {
+		t.Fatalf("wrong error, expected: %+v, got: %+v", expected, typeErr)
+	}
+	if err.Error() != "Global ID of type Invoice, expected Order or Receipt" {
+		t.Fatalf("wrong error message, got: %v", err)
+	}
+}
+func TestFromGlobalIDParts_ReturnsErrorForBadlyEscapedParts(t *testing.T) {
+	_, _, err := relay.FromGlobalIDParts(relay.ToGlobalID("Invoice", "1:%zz"))
+	var partErr *relay.GlobalIDPartError
+	if !errors.As(err, &partErr) || partErr.Part != 1 || partErr.Value != "%zz" {
+		t.Fatalf("expected *GlobalIDPartError for part 1, got: %v", err)
+	}
+}
+func TestFromGlobalIDInt64_ParsesIntegerIDs(t *testing.T) {
+	id, err := relay.FromGlobalIDInt64(relay.ToGlobalID("User", "9007199254740993"), "User")
+	if err != nil || id != 9007199254740993 {
+		t.Fatalf("wrong ID, got: %v, %v", id, err)
+	}
+
+	_, err = relay.FromGlobalIDInt64(relay.ToGlobalID("User", "abc"), "User")
+	var partErr *relay.GlobalIDPartError
+	if !errors.As(err, &partErr) || partErr.Value != "abc" {
+		t.Fatalf("expected *GlobalIDPartError, got: %v", err)
+	}
+
+	_, err = relay.FromGlobalIDInt64(relay.ToGlobalID("Photo", "1"), "User")
+	var typeErr *relay.GlobalIDTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected *GlobalIDTypeError, got: %v", err)
+	}
+
+	if _, err = relay.FromGlobalIDInt64("invalid"); err == nil {
+		t.Fatalf("expected error for invalid global ID")
+	}
+}
+func TestFromGlobalIDUUID_ParsesUUIDs(t *testing.T) {
+	uuid, err := relay.FromGlobalIDUUID(relay.ToGlobalID("Order", "6BA7B810-9dad-11d1-80b4-00c04fd430c8"), "Order")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	if uuid != expected {
+		t.Fatalf("wrong UUID, expected: %x, got: %x", expected, uuid)
+	}
+
+	for _, id := range []string{"6ba7b810", "6ba7b8109dad11d180b400c04fd430c8", "6ba7b810-9dad-11d1-80b4-00c04fd430cz"} {
+		_, err := relay.FromGlobalIDUUID(relay.ToGlobalID("Order", id))
+		var partErr *relay.GlobalIDPartError
+		if !errors.As(err, &partErr) {
+			t.Fatalf("expected *GlobalIDPartError for %v, got: %v", id, err)
+		}
+	}
+}
+func TestDecodeGlobalID_ReturnsSentinelErrors(t *testing.T) {
+	tests := []struct {
+		globalID string
+		expected error
+	}{
+		{"not base64!", relay.ErrInvalidGlobalIDEncoding},
+		{"VXNlcg==", relay.ErrMissingGlobalIDSeparator},
+		{relay.ToGlobalID("", "1"), relay.ErrMissingGlobalIDSeparator},
+		{relay.ToGlobalID("Faction", "1"), relay.ErrUnexpectedGlobalIDType},
+	}
+	for _, test := range tests {
+		_, err := relay.DecodeGlobalID(test.globalID, "Ship")
+		if !errors.Is(err, test.expected) {
+			t.Fatalf("wrong error for %v, expected: %v, got: %v", test.globalID, test.expected, err)
+		}
+	}
+
+	resolvedID, err := relay.DecodeGlobalID(relay.ToGlobalID("Ship", "urn:ship:1"), "Faction", "Ship")
+	expected := relay.ResolvedGlobalID{Type: "Ship", ID: "urn:ship:1"}
+	if err != nil || resolvedID != expected {
+		t.Fatalf("wrong resolved ID, expected: %+v, got: %+v, %v", expected, resolvedID, err)
+	}
+}
+func TestDecodeGlobalID_TypedDecodersMatchTheSentinels(t *testing.T) {
+	if _, err := relay.FromGlobalIDInt64(relay.ToGlobalID("Faction", "1"), "Ship"); !errors.Is(err, relay.ErrUnexpectedGlobalIDType) {
+		t.Fatalf("expected ErrUnexpectedGlobalIDType, got: %v", err)
+	}
+	if _, err := relay.FromGlobalIDUUID("not base64!"); !errors.Is(err, relay.ErrInvalidGlobalIDEncoding) {
+		t.Fatalf("expected ErrInvalidGlobalIDEncoding, got: %v", err)
+	}
+	if _, _, err := relay.FromGlobalIDParts("VXNlcg=="); !errors.Is(err, relay.ErrMissingGlobalIDSeparator) {
+		t.Fatalf("expected ErrMissingGlobalIDSeparator, got: %v", err)
+	}
+}
+func TestNewGlobalIDScalar_ValidatesMutationInputs(t *testing.T) {
+	var input map[string]interface{}
+	mutation := relay.MutationWithClientMutationID(relay.MutationConfig{
+		Name: "RenameShip",
+		InputFields: graphql.InputObjectConfigFieldMap{
+			"shipId": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(relay.NewGlobalIDScalar("ShipID", "Ship")),
+			},
+		},
+		OutputFields: graphql.Fields{
+			"shipId": &graphql.Field{
+				Type: graphql.ID,
+			},
+		},
+		MutateAndGetPayload: func(inputMap map[string]interface{}, info graphql.ResolveInfo, ctx context.Context) (map[string]interface{}, error) {
+			input = inputMap
+			return map[string]interface{}{
+				"shipId": inputMap["shipId"].(relay.ResolvedGlobalID).ID,
+			}, nil
+		},
+	})
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"renameShip": mutation,
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    mutationType,
+		Mutation: mutationType,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := `
+      mutation M($shipId: ShipID!) {
+        renameShip(input: {clientMutationId: "abc", shipId: $shipId}) {
+          shipId
+        }
+      }
+    `
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  query,
+		VariableValues: map[string]interface{}{"shipId": relay.ToGlobalID("Ship", "1")},
+	})
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"renameShip": map[string]interface{}{
+				"shipId": "1",
+			},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, expected: %v, got: %v", expected, result)
+	}
+	if input["shipId"] != (relay.ResolvedGlobalID{Type: "Ship", ID: "1"}) {
+		t.Fatalf("wrong input, got: %v", input)
+	}
+
+	for _, shipID := range []string{relay.ToGlobalID("Faction", "1"), "invalid"} {
+		input = nil
+		result = graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  query,
+			VariableValues: map[string]interface{}{"shipId": shipID},
+		})
+		if len(result.Errors) == 0 || input != nil {
+			t.Fatalf("expected %v to be rejected, got: %v", shipID, result)
+		}
+	}
+
+	result = graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `mutation { renameShip(input: {clientMutationId: "abc", shipId: "` + relay.ToGlobalID("Faction", "1") + `"}) { shipId } }`,
+	})
+	if len(result.Errors) == 0 || input != nil {
+		t.Fatalf("expected literal to be rejected, got: %v", result)
+	}
+}
@@ -0,0 +1,205 @@
+package relay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const KEYSET_PREFIX = "keyset:"
+
+// Returns the sort-key values of a node, in the order the data source sorts by.
+type KeysetKeyFn func(node interface{}) []interface{}
+
+// Fetches a page of rows from a sorted data source.
+type KeysetFetchFn func(query KeysetQuery) ([]interface{}, error)
+
+/*
+Describes the rows a `KeysetFetchFn` has to return.
+
+Rows must be strictly after `After` and strictly before `Before` in the sort
+order; a nil key means the range is unbounded on that side. When `Reverse` is
+false, rows are returned in sort order starting right after `After`. When
+`Reverse` is true, rows are returned in reverse sort order starting right
+before `Before`. At most `Limit` rows are returned, -1 for no limit.
+
+Keys decoded from cursors hold numbers as `json.Number`.
+*/
+type KeysetQuery struct {
+	After   []interface{} `json:"after"`
+	Before  []interface{} `json:"before"`
+	Limit   int           `json:"limit"`
+	Reverse bool          `json:"reverse"`
+}
+
+/*
+Returns a connection object for use in GraphQL, paginated by the sort keys of
+the rows instead of by their offsets. Page sizes are checked against the
+PaginationPolicy in `opts`, if any.
+
+Each cursor encodes the sort-key values of its row, so pagination stays
+stable when rows are inserted or deleted between requests. The data source is
+asked for one extra row to find out whether `hasNextPage` (when paginating
+forwards) or `hasPreviousPage` (when paginating backwards) is true.
+
+With `ConnectionOptions.BidirectionalPageInfo`, the flag of the other
+direction is found with one more fetch of a single row, made only when a
+cursor bounds the page on that side.
+
+The cursors are signed or encrypted by the `CursorCodec` in `opts`, which must
+then be a PayloadCursorCodec; other codecs are an error.
+*/
+func ConnectionFromKeyset(fetch KeysetFetchFn, key KeysetKeyFn, args ConnectionArguments, opts ...ConnectionOptions) (*Connection, error) {
+	options := mergeConnectionOptions(opts)
+	args, err := options.PaginationPolicy.Apply(args)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := options.payloadCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	query := KeysetQuery{
+		Limit: -1,
+	}
+	if args.After != "" {
+		cursor, err := decodePayloadCursor(codec, args.After)
+		if err != nil {
+			return nil, err
+		}
+		after, err := CursorToKeys(cursor)
+		if err != nil {
+			return nil, err
+		}
+		query.After = after
+	}
+	if args.Before != "" {
+		cursor, err := decodePayloadCursor(codec, args.Before)
+		if err != nil {
+			return nil, err
+		}
+		before, err := CursorToKeys(cursor)
+		if err != nil {
+			return nil, err
+		}
+		query.Before = before
+	}
+
+	if args.First != -1 {
+		query.Limit = args.First + 1
+	} else if args.Last != -1 {
+		query.Limit = args.Last + 1
+		query.Reverse = true
+	}
+
+	rows, err := fetch(query)
+	if err != nil {
+		return nil, err
+	}
+
+	hasPreviousPage := false
+	hasNextPage := false
+	if query.Reverse {
+		if len(rows) > args.Last {
+			hasPreviousPage = true
+			rows = rows[:args.Last]
+		}
+		reversed := make([]interface{}, 0, len(rows))
+		for i := len(rows) - 1; i >= 0; i-- {
+			reversed = append(reversed, rows[i])
+		}
+		rows = reversed
+	} else {
+		if args.First != -1 && len(rows) > args.First {
+			hasNextPage = true
+			rows = rows[:args.First]
+		}
+		if args.Last != -1 && len(rows) > args.Last {
+			hasPreviousPage = true
+			rows = rows[len(rows)-args.Last:]
+		}
+	}
+
+	if options.BidirectionalPageInfo {
+		// an empty page lies between the cursors, so the rows before it are
+		// those before `before`, and the rows after it those after `after`
+		if !hasPreviousPage && query.After != nil {
+			lookahead := KeysetQuery{Before: query.Before, Limit: 1, Reverse: true}
+			if len(rows) > 0 {
+				lookahead.Before = key(rows[0])
+			}
+			if hasPreviousPage, err = keysetRowExists(fetch, lookahead); err != nil {
+				return nil, err
+			}
+		}
+		if !hasNextPage && query.Before != nil {
+			lookahead := KeysetQuery{After: query.After, Limit: 1}
+			if len(rows) > 0 {
+				lookahead.After = key(rows[len(rows)-1])
+			}
+			if hasNextPage, err = keysetRowExists(fetch, lookahead); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	edges := []*Edge{}
+	for _, row := range rows {
+		edges = append(edges, &Edge{
+			Cursor: encodePayloadCursor(codec, KeysToCursor(key(row))),
+			Node:   row,
+		})
+	}
+
+	var firstEdgeCursor, lastEdgeCursor ConnectionCursor
+	if len(edges) > 0 {
+		firstEdgeCursor = edges[0].Cursor
+		lastEdgeCursor = edges[len(edges)-1].Cursor
+	}
+
+	conn := NewConnection()
+	conn.Edges = edges
+	conn.PageInfo = PageInfo{
+		StartCursor:     firstEdgeCursor,
+		EndCursor:       lastEdgeCursor,
+		HasPreviousPage: hasPreviousPage,
+		HasNextPage:     hasNextPage,
+	}
+	return conn, nil
+}
+
+func keysetRowExists(fetch KeysetFetchFn, query KeysetQuery) (bool, error) {
+	rows, err := fetch(query)
+	if err != nil {
+		return false, err
+	}
+	return len(rows) > 0, nil
+}
+
+// Creates the cursor string from the sort-key values of a row.
+func KeysToCursor(keys []interface{}) ConnectionCursor {
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return ""
+	}
+	str := KEYSET_PREFIX + string(b)
+	return ConnectionCursor(base64.StdEncoding.EncodeToString([]byte(str)))
+}
+
+// Re-derives the sort-key values from the cursor string.
+func CursorToKeys(cursor ConnectionCursor) ([]interface{}, error) {
+	b, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil || !strings.HasPrefix(string(b), KEYSET_PREFIX) {
+		return nil, errors.New("Invalid cursor")
+	}
+	keys := []interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(b[len(KEYSET_PREFIX):]))
+	decoder.UseNumber()
+	if err := decoder.Decode(&keys); err != nil {
+		return nil, errors.New("Invalid cursor")
+	}
+	return keys, nil
+}
@@ -0,0 +1,353 @@
+package relay_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/relay"
+)
+
+type keysetTestRow struct {
+	ID   int
+	Name string
+}
+
+var keysetTestRows = []*keysetTestRow{
+	&keysetTestRow{10, "A"},
+	&keysetTestRow{20, "B"},
+	&keysetTestRow{30, "C"},
+	&keysetTestRow{40, "D"},
+	&keysetTestRow{50, "E"},
+}
+
+func keysetTestKey(node interface{}) []interface{} {
+	return []interface{}{node.(*keysetTestRow).ID}
+}
+
+func keysetTestKeyValue(keys []interface{}) int {
+	switch key := keys[0].(type) {
+	case json.Number:
+		i, _ := key.Int64()
+		return int(i)
+	case int:
+		return key
+	}
+	return 0
+}
+
+// keysetTestFetch serves rows from a slice sorted by ID, and records the
+// queries it receives.
+func keysetTestFetch(rows []*keysetTestRow, queries *[]relay.KeysetQuery) relay.KeysetFetchFn {
+	return func(query relay.KeysetQuery) ([]interface{}, error) {
+		*queries = append(*queries, query)
+		matching := []interface{}{}
+		for _, row := range rows {
+			if query.After != nil && row.ID <= keysetTestKeyValue(query.After) {
+				continue
+			}
+			if query.Before != nil && row.ID >= keysetTestKeyValue(query.Before) {
+				continue
+			}
+			matching = append(matching, row)
+		}
+		if query.Reverse {
+			for i, j := 0, len(matching)-1; i < j; i, j = i+1, j-1 {
+				matching[i], matching[j] = matching[j], matching[i]
+			}
+		}
+		if query.Limit != -1 && len(matching) > query.Limit {
+			matching = matching[:query.Limit]
+		}
+		return matching, nil
+	}
+}
+
+// keysetTestOffsetCodec is a CursorCodec that can only encode offsets.
+type keysetTestOffsetCodec struct {
+	relay.Base64CursorCodec
+}
+
+func keysetTestNames(conn *relay.Connection) []string {
+	names := []string{}
+	for _, edge := range conn.Edges {
+		names = append(names, edge.Node.(*keysetTestRow).Name)
+	}
+	return names
+}
+
+func TestKeysToCursor_RoundTripsKeys(t *testing.T) {
+	cursor := relay.KeysToCursor([]interface{}{"2015-01-01", 42})
+	keys, err := relay.CursorToKeys(cursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []interface{}{"2015-01-01", json.Number("42")}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("wrong keys, expected: %v, got: %v", expected, keys)
+	}
+}
+func TestCursorToKeys_RejectsOffsetCursors(t *testing.T) {
+	_, err := relay.CursorToKeys(relay.OffsetToCursor(1))
+	if err == nil {
+		t.Fatalf("expected error for offset cursor")
+	}
+}
+func TestConnectionFromKeyset_RespectsFirstAndAfter(t *testing.T) {
+	queries := []relay.KeysetQuery{}
+	fetch := keysetTestFetch(keysetTestRows, &queries)
+
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	})
+	conn, err := relay.ConnectionFromKeyset(fetch, keysetTestKey, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := keysetTestNames(conn); !reflect.DeepEqual(names, []string{"A", "B"}) {
+		t.Fatalf("wrong first page, got: %v", names)
+	}
+	if !conn.PageInfo.HasNextPage || conn.PageInfo.HasPreviousPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+	if queries[0].Limit != 3 {
+		t.Fatalf("expected one extra row to be fetched, got limit: %v", queries[0].Limit)
+	}
+
+	args = relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(conn.PageInfo.EndCursor),
+	})
+	conn, err = relay.ConnectionFromKeyset(fetch, keysetTestKey, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := keysetTestNames(conn); !reflect.DeepEqual(names, []string{"C", "D"}) {
+		t.Fatalf("wrong second page, got: %v", names)
+	}
+	if !conn.PageInfo.HasNextPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+}
+func TestConnectionFromKeyset_IsStableWhenRowsAreInserted(t *testing.T) {
+	queries := []relay.KeysetQuery{}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	})
+	conn, err := relay.ConnectionFromKeyset(keysetTestFetch(keysetTestRows, &queries), keysetTestKey, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a row is inserted at the start of the list between two requests
+	rows := append([]*keysetTestRow{&keysetTestRow{5, "Z"}}, keysetTestRows...)
+	args = relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(conn.PageInfo.EndCursor),
+	})
+	conn, err = relay.ConnectionFromKeyset(keysetTestFetch(rows, &queries), keysetTestKey, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := keysetTestNames(conn); !reflect.DeepEqual(names, []string{"C", "D"}) {
+		t.Fatalf("wrong second page, got: %v", names)
+	}
+}
+func TestConnectionFromKeyset_RespectsLastAndBefore(t *testing.T) {
+	queries := []relay.KeysetQuery{}
+	fetch := keysetTestFetch(keysetTestRows, &queries)
+
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"last":   2,
+		"before": string(relay.KeysToCursor([]interface{}{40})),
+	})
+	conn, err := relay.ConnectionFromKeyset(fetch, keysetTestKey, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := keysetTestNames(conn); !reflect.DeepEqual(names, []string{"B", "C"}) {
+		t.Fatalf("wrong page, got: %v", names)
+	}
+	if !conn.PageInfo.HasPreviousPage || conn.PageInfo.HasNextPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+	if !queries[0].Reverse || queries[0].Limit != 3 {
+		t.Fatalf("wrong query, got: %+v", queries[0])
+	}
+}
+func TestConnectionFromKeyset_ReturnsErrorForInvalidCursor(t *testing.T) {
+	queries := []relay.KeysetQuery{}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"after": "invalid",
+	})
+	_, err := relay.ConnectionFromKeyset(keysetTestFetch(keysetTestRows, &queries), keysetTestKey, args)
+	if err == nil {
+		t.Fatalf("expected error for invalid cursor")
+	}
+	if len(queries) != 0 {
+		t.Fatalf("expected no fetch, got: %v", queries)
+	}
+}
+func TestConnectionFromKeyset_BidirectionalPageInfo_FetchesOneLookaheadRow(t *testing.T) {
+	queries := []relay.KeysetQuery{}
+	fetch := keysetTestFetch(keysetTestRows, &queries)
+	options := relay.ConnectionOptions{
+		BidirectionalPageInfo: true,
+	}
+
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(relay.KeysToCursor([]interface{}{10})),
+	})
+	conn, err := relay.ConnectionFromKeyset(fetch, keysetTestKey, args, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+	if len(queries) != 2 || queries[1].Limit != 1 || !queries[1].Reverse || keysetTestKeyValue(queries[1].Before) != 20 {
+		t.Fatalf("wrong lookahead query, got: %+v", queries)
+	}
+
+	queries = queries[:0]
+	args = relay.NewConnectionArguments(map[string]interface{}{
+		"last":   2,
+		"before": string(relay.KeysToCursor([]interface{}{50})),
+	})
+	conn, err = relay.ConnectionFromKeyset(fetch, keysetTestKey, args, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+
+	// without a cursor on the other side, there is nothing to look up
+	queries = queries[:0]
+	args = relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	})
+	conn, err = relay.ConnectionFromKeyset(fetch, keysetTestKey, args, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conn.PageInfo.HasPreviousPage || len(queries) != 1 {
+		t.Fatalf("unexpected lookahead: %+v, %+v", conn.PageInfo, queries)
+	}
+}
+func TestConnectionFromKeyset_SignsCursorsWithPayloadCodec(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options := relay.ConnectionOptions{
+		CursorCodec: codec,
+	}
+	queries := []relay.KeysetQuery{}
+	fetch := keysetTestFetch(keysetTestRows, &queries)
+
+	conn, err := relay.ConnectionFromKeyset(fetch, keysetTestKey, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	}), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := relay.CursorToKeys(conn.PageInfo.EndCursor); err == nil {
+		t.Fatalf("expected cursor to be signed, got: %v", conn.PageInfo.EndCursor)
+	}
+
+	conn, err = relay.ConnectionFromKeyset(fetch, keysetTestKey, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(conn.PageInfo.EndCursor),
+	}), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := keysetTestNames(conn); !reflect.DeepEqual(names, []string{"C", "D"}) {
+		t.Fatalf("wrong page, got: %v", names)
+	}
+
+	_, err = relay.ConnectionFromKeyset(fetch, keysetTestKey, relay.NewConnectionArguments(map[string]interface{}{
+		"after": string(relay.KeysToCursor([]interface{}{40})),
+	}), options)
+	var signatureErr *relay.CursorSignatureError
+	if !errors.As(err, &signatureErr) {
+		t.Fatalf("expected *CursorSignatureError for unsigned cursor, got: %v", err)
+	}
+}
+func TestConnectionFromKeyset_RejectsOffsetOnlyCodecs(t *testing.T) {
+	queries := []relay.KeysetQuery{}
+	_, err := relay.ConnectionFromKeyset(keysetTestFetch(keysetTestRows, &queries), keysetTestKey, relay.NewConnectionArguments(nil), relay.ConnectionOptions{
+		CursorCodec: keysetTestOffsetCodec{},
+	})
+	if err == nil {
+		t.Fatalf("expected error for a codec that can only encode offsets")
+	}
+	if len(queries) != 0 {
+		t.Fatalf("expected no fetch, got: %v", queries)
+	}
+}
+func TestConnectionFromKeyset_MatchesConnectionFromArray(t *testing.T) {
+	// the cursors of both helpers, by offset; cursors past the ends are left
+	// out, as keyset cursors hold the keys of existing rows
+	keysetCursors := []interface{}{nil}
+	arrayCursors := []interface{}{nil}
+	for offset, row := range keysetTestRows {
+		keysetCursors = append(keysetCursors, string(relay.KeysToCursor([]interface{}{row.ID})))
+		arrayCursors = append(arrayCursors, string(relay.OffsetToCursor(offset)))
+	}
+	sizes := []interface{}{nil, 0, 1, 2, 10}
+	data := []interface{}{}
+	for _, row := range keysetTestRows {
+		data = append(data, row)
+	}
+
+	for after := range keysetCursors {
+		for before := range keysetCursors {
+			if after > 0 && before > 0 && before <= after {
+				// crossed cursors make ConnectionFromArray give up early
+				continue
+			}
+			for _, first := range sizes {
+				for _, last := range sizes {
+					keysetFilter := map[string]interface{}{}
+					arrayFilter := map[string]interface{}{}
+					for name, value := range map[string]interface{}{"first": first, "last": last} {
+						if value != nil {
+							keysetFilter[name] = value
+							arrayFilter[name] = value
+						}
+					}
+					if after > 0 {
+						keysetFilter["after"] = keysetCursors[after]
+						arrayFilter["after"] = arrayCursors[after]
+					}
+					if before > 0 {
+						keysetFilter["before"] = keysetCursors[before]
+						arrayFilter["before"] = arrayCursors[before]
+					}
+					for _, options := range []relay.ConnectionOptions{{}, {BidirectionalPageInfo: true}} {
+						queries := []relay.KeysetQuery{}
+						conn, err := relay.ConnectionFromKeyset(keysetTestFetch(keysetTestRows, &queries), keysetTestKey, relay.NewConnectionArguments(keysetFilter), options)
+						if err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+						expected := relay.ConnectionFromArray(data, relay.NewConnectionArguments(arrayFilter), options)
+						expectedNames := []string{}
+						for _, edge := range expected.Edges {
+							expectedNames = append(expectedNames, edge.Node.(*keysetTestRow).Name)
+						}
+						if names := keysetTestNames(conn); !reflect.DeepEqual(names, expectedNames) {
+							t.Fatalf("wrong page for %v, %+v, expected: %v, got: %v", arrayFilter, options, expectedNames, names)
+						}
+						if conn.PageInfo.HasPreviousPage != expected.PageInfo.HasPreviousPage || conn.PageInfo.HasNextPage != expected.PageInfo.HasNextPage {
+							t.Fatalf("wrong page info for %v, %+v, expected: %+v, got: %+v", arrayFilter, options, expected.PageInfo, conn.PageInfo)
+						}
+					}
+				}
+			}
+		}
+	}
+}
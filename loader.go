@@ -0,0 +1,155 @@
+package relay
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+/*
+Fetches the values of several keys at once, returning them in the order of
+the keys: nil for keys without a value, or an `error` value for keys that
+failed on their own. An error returned by the function fails every key.
+*/
+type BatchLoadFn func(ctx context.Context, keys []string) ([]interface{}, error)
+
+// Returns a thunk resolving to the value of a key, as returned by Loader.Load.
+type LoadFn func(ctx context.Context, key string) func() (interface{}, error)
+
+/*
+Batches and caches the fetching of values by key, in the style of DataLoader.
+
+`Load` queues a key and returns a thunk; the first thunk called fetches all
+queued keys with a single call of the batch function. graphql-go calls the
+thunks returned by resolvers only once the fields that do not return thunks
+have been resolved, so the keys loaded by all those fields are fetched in one
+batch. Values are cached for the lifetime of the Loader, which should
+therefore be scoped to a request: see WithLoaders and LoaderFromContext.
+*/
+type Loader struct {
+	batch   BatchLoadFn
+	mu      sync.Mutex
+	entries map[string]*loaderEntry
+	pending []string
+}
+
+type loaderEntry struct {
+	value interface{}
+	err   error
+	ready chan struct{}
+}
+
+func NewLoader(batch BatchLoadFn) *Loader {
+	return &Loader{
+		batch:   batch,
+		entries: map[string]*loaderEntry{},
+	}
+}
+
+// Queues the key, unless it was loaded already, and returns a thunk resolving
+// to its value.
+func (l *Loader) Load(ctx context.Context, key string) func() (interface{}, error) {
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &loaderEntry{ready: make(chan struct{})}
+		l.entries[key] = entry
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		select {
+		case <-entry.ready:
+		default:
+			l.dispatch(ctx)
+			<-entry.ready
+		}
+		return entry.value, entry.err
+	}
+}
+
+// Fetches the queued keys.
+func (l *Loader) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	entries := []*loaderEntry{}
+	for _, key := range keys {
+		entries = append(entries, l.entries[key])
+	}
+	l.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+
+	// the entries are released even if the batch function panics, so that
+	// the thunks waiting on them do not block forever
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		r := recover()
+		for _, entry := range entries {
+			entry.err = fmt.Errorf("Batch function panicked: %v", r)
+			close(entry.ready)
+		}
+		panic(r)
+	}()
+
+	values, err := l.batch(ctx, keys)
+	if err == nil && len(values) != len(keys) {
+		err = fmt.Errorf("Batch function returned %v values for %v keys", len(values), len(keys))
+	}
+	for i, entry := range entries {
+		if err != nil {
+			entry.err = err
+		} else if valueErr, ok := values[i].(error); ok {
+			entry.err = valueErr
+		} else {
+			entry.value = values[i]
+		}
+		close(entry.ready)
+	}
+	completed = true
+}
+
+type loadersContextKey struct{}
+
+type loaderRegistry struct {
+	mu      sync.Mutex
+	loaders map[interface{}]*Loader
+}
+
+/*
+Returns a context holding request-scoped loaders. Pass it as
+`graphql.Params.Context` so the loaders of NodeDefinitions and of
+LoaderFromContext batch and cache within the request.
+*/
+func WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersContextKey{}, &loaderRegistry{
+		loaders: map[interface{}]*Loader{},
+	})
+}
+
+/*
+Returns the loader of the request identified by `key`, creating it with the
+batch function on first use. Without WithLoaders, a new loader is returned on
+every call: values are still loaded, but not batched across fields.
+*/
+func LoaderFromContext(ctx context.Context, key interface{}, batch BatchLoadFn) *Loader {
+	registry, ok := ctx.Value(loadersContextKey{}).(*loaderRegistry)
+	if !ok {
+		return NewLoader(batch)
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	loader, ok := registry.loaders[key]
+	if !ok {
+		loader = NewLoader(batch)
+		registry.loaders[key] = loader
+	}
+	return loader
+}
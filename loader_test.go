@@ -0,0 +1,249 @@
+package relay_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+// loaderTestBatch doubles keys, failing on "error", and records the batches it gets.
+func loaderTestBatch(batches *[][]string) relay.BatchLoadFn {
+	return func(ctx context.Context, keys []string) ([]interface{}, error) {
+		*batches = append(*batches, keys)
+		values := []interface{}{}
+		for _, key := range keys {
+			if key == "error" {
+				values = append(values, errors.New("cannot load"))
+			} else {
+				values = append(values, key+key)
+			}
+		}
+		return values, nil
+	}
+}
+
+func TestLoader_BatchesQueuedKeys(t *testing.T) {
+	batches := [][]string{}
+	loader := relay.NewLoader(loaderTestBatch(&batches))
+	ctx := context.Background()
+
+	a := loader.Load(ctx, "a")
+	b := loader.Load(ctx, "b")
+	alsoA := loader.Load(ctx, "a")
+	fails := loader.Load(ctx, "error")
+	if len(batches) != 0 {
+		t.Fatalf("expected no batch before a thunk is called, got: %v", batches)
+	}
+
+	tests := []struct {
+		thunk    func() (interface{}, error)
+		expected interface{}
+	}{{a, "aa"}, {b, "bb"}, {alsoA, "aa"}}
+	for _, test := range tests {
+		value, err := test.thunk()
+		if err != nil || value != test.expected {
+			t.Fatalf("wrong value, expected: %v, got: %v, %v", test.expected, value, err)
+		}
+	}
+	if _, err := fails(); err == nil || err.Error() != "cannot load" {
+		t.Fatalf("expected key error, got: %v", err)
+	}
+
+	// loaded keys are cached
+	if value, _ := loader.Load(ctx, "b")(); value != "bb" {
+		t.Fatalf("wrong cached value, got: %v", value)
+	}
+	c, _ := loader.Load(ctx, "c")()
+	if c != "cc" {
+		t.Fatalf("wrong value, got: %v", c)
+	}
+	expected := [][]string{{"a", "b", "error"}, {"c"}}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Fatalf("wrong batches, expected: %v, got: %v", expected, batches)
+	}
+}
+func TestLoader_FailsEveryKeyOnBatchErrors(t *testing.T) {
+	loader := relay.NewLoader(func(ctx context.Context, keys []string) ([]interface{}, error) {
+		return []interface{}{"only one"}, nil
+	})
+	a := loader.Load(context.Background(), "a")
+	b := loader.Load(context.Background(), "b")
+	for _, thunk := range []func() (interface{}, error){a, b} {
+		if _, err := thunk(); err == nil {
+			t.Fatalf("expected error for mismatched values")
+		}
+	}
+}
+func TestLoader_ReleasesKeysWhenTheBatchFunctionPanics(t *testing.T) {
+	loader := relay.NewLoader(func(ctx context.Context, keys []string) ([]interface{}, error) {
+		panic("database is gone")
+	})
+	a := loader.Load(context.Background(), "a")
+	b := loader.Load(context.Background(), "b")
+
+	func() {
+		defer func() {
+			if r := recover(); r != "database is gone" {
+				t.Fatalf("expected the panic to be propagated, got: %v", r)
+			}
+		}()
+		a()
+	}()
+
+	done := make(chan error)
+	go func() {
+		_, err := b()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "Batch function panicked: database is gone" {
+			t.Fatalf("expected panic error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the thunk of the other key not to block")
+	}
+}
+func TestLoaderFromContext_IsScopedToTheRequest(t *testing.T) {
+	batches := [][]string{}
+	ctx := relay.WithLoaders(context.Background())
+	if relay.LoaderFromContext(ctx, "letters", loaderTestBatch(&batches)) != relay.LoaderFromContext(ctx, "letters", loaderTestBatch(&batches)) {
+		t.Fatalf("expected the same loader within a request")
+	}
+	if relay.LoaderFromContext(ctx, "letters", loaderTestBatch(&batches)) == relay.LoaderFromContext(ctx, "numbers", loaderTestBatch(&batches)) {
+		t.Fatalf("expected a loader per key")
+	}
+	other := relay.WithLoaders(context.Background())
+	if relay.LoaderFromContext(ctx, "letters", loaderTestBatch(&batches)) == relay.LoaderFromContext(other, "letters", loaderTestBatch(&batches)) {
+		t.Fatalf("expected a loader per request")
+	}
+}
+func TestNodeDefinitions_BatchesNodeAndConnectionNodes(t *testing.T) {
+	users := map[string]*user{
+		relay.ToGlobalID("User", "1"): &user{ID: 1, Name: "John Doe"},
+		relay.ToGlobalID("User", "2"): &user{ID: 2, Name: "Jane Smith"},
+		relay.ToGlobalID("User", "3"): &user{ID: 3, Name: "Joe Bloggs"},
+	}
+	batches := [][]string{}
+	var userType *graphql.Object
+	nodeDefinitions := relay.NewNodeDefinitions(relay.NodeDefinitionsConfig{
+		BatchIDFetcher: func(ids []string, ctx context.Context) ([]interface{}, error) {
+			batches = append(batches, ids)
+			nodes := []interface{}{}
+			for _, id := range ids {
+				if u, ok := users[id]; ok {
+					nodes = append(nodes, u)
+				} else {
+					nodes = append(nodes, nil)
+				}
+			}
+			return nodes, nil
+		},
+		TypeResolve: func(p graphql.ResolveTypeParams) *graphql.Object {
+			return userType
+		},
+	})
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": relay.GlobalIDField("User", nil),
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+		Interfaces: []*graphql.Interface{nodeDefinitions.NodeInterface},
+	})
+	userConnection := relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name:       "User",
+		NodeType:   userType,
+		NodeLoader: nodeDefinitions.LoadNode,
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"node": nodeDefinitions.NodeField,
+				"users": &graphql.Field{
+					Type: userConnection.ConnectionType,
+					Args: relay.ConnectionArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						ids := []interface{}{
+							relay.ToGlobalID("User", "1"),
+							relay.ToGlobalID("User", "2"),
+							relay.ToGlobalID("User", "3"),
+						}
+						return relay.ConnectionFromArray(ids, relay.NewConnectionArguments(p.Args)), nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := `{
+      first: node(id: "VXNlcjox") {
+        ... on User {
+          name
+        }
+      }
+      missing: node(id: "VXNlcjo0") {
+        id
+      }
+      users(first: 2) {
+        edges {
+          node {
+            name
+          }
+        }
+      }
+    }`
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"first": map[string]interface{}{
+				"name": "John Doe",
+			},
+			"missing": nil,
+			"users": map[string]interface{}{
+				"edges": []interface{}{
+					map[string]interface{}{
+						"node": map[string]interface{}{
+							"name": "John Doe",
+						},
+					},
+					map[string]interface{}{
+						"node": map[string]interface{}{
+							"name": "Jane Smith",
+						},
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+		Context:       relay.WithLoaders(context.Background()),
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+	// the `node` fields and the connection nodes are fetched in a single
+	// batch, where the node of the first edge is not queued twice; fields
+	// are resolved in no particular order
+	for _, batch := range batches {
+		sort.Strings(batch)
+	}
+	expectedBatches := [][]string{{"VXNlcjo0", "VXNlcjox", "VXNlcjoy"}}
+	if !reflect.DeepEqual(batches, expectedBatches) {
+		t.Fatalf("wrong batches, expected: %v, got: %v", expectedBatches, batches)
+	}
+}
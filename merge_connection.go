@@ -0,0 +1,202 @@
+package relay
+
+import (
+	"container/heap"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+const MERGE_PREFIX = "mergeconnection:"
+
+// Orders two nodes: negative if a comes first, positive if b comes first,
+// zero if they are equivalent.
+type CompareFn func(a, b interface{}) int
+
+/*
+Returns a connection object for the union of several sources, each already
+ordered by `compare`, merged into a single ordered list.
+
+Each cursor records how many items of every source come before it, so
+`first`/`after` pagination across the union stays correct even when the
+sources hold equivalent items. Each source is fetched once, for at most
+`first + 1` items, the extra item deciding `hasNextPage`; without `first`,
+the sources are counted and read to their end, and `TotalCount` is set to
+the sum of their counts; it is left nil otherwise. Only forward pagination is
+supported: `last` and `before` return an error.
+
+As with ConnectionFromKeyset, a `CursorCodec` in `opts` must be a
+PayloadCursorCodec.
+*/
+func MergeConnections(ctx context.Context, sources []Paginator, compare CompareFn, args ConnectionArguments, opts ...ConnectionOptions) (*Connection, error) {
+	options := mergeConnectionOptions(opts)
+	args, err := options.PaginationPolicy.Apply(args)
+	if err != nil {
+		return nil, err
+	}
+	if args.Last != -1 || args.Before != "" {
+		return nil, errors.New("Merged connections only support forward pagination")
+	}
+	codec, err := options.payloadCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]int, len(sources))
+	if args.After != "" {
+		cursor, err := decodePayloadCursor(codec, args.After)
+		if err != nil {
+			return nil, err
+		}
+		positions, err = CursorToPositions(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if len(positions) != len(sources) {
+			return nil, errors.New("Invalid cursor")
+		}
+	}
+
+	merged := &mergeHeap{compare: compare}
+	totalCount := 0
+	for i, source := range sources {
+		limit := args.First + 1
+		if args.First == -1 {
+			count, err := source.Count(ctx)
+			if err != nil {
+				return nil, err
+			}
+			totalCount += count
+			limit = count - positions[i]
+		}
+		if limit <= 0 {
+			continue
+		}
+		items, err := source.Fetch(ctx, positions[i], limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) > 0 {
+			merged.sources = append(merged.sources, &mergeSource{index: i, items: items})
+		}
+	}
+	heap.Init(merged)
+
+	edges := []*Edge{}
+	hasNextPage := false
+	for merged.Len() > 0 {
+		if args.First != -1 && len(edges) == args.First {
+			hasNextPage = true
+			break
+		}
+		source := merged.sources[0]
+		node := source.items[0]
+		source.items = source.items[1:]
+		if len(source.items) == 0 {
+			heap.Pop(merged)
+		} else {
+			heap.Fix(merged, 0)
+		}
+		positions[source.index]++
+		edges = append(edges, &Edge{
+			Cursor: encodePayloadCursor(codec, PositionsToCursor(positions)),
+			Node:   node,
+		})
+	}
+
+	hasPreviousPage := false
+	if options.BidirectionalPageInfo && args.After != "" {
+		for _, position := range positions {
+			if position > 0 {
+				hasPreviousPage = true
+			}
+		}
+	}
+
+	var firstEdgeCursor, lastEdgeCursor ConnectionCursor
+	if len(edges) > 0 {
+		firstEdgeCursor = edges[0].Cursor
+		lastEdgeCursor = edges[len(edges)-1].Cursor
+	}
+
+	conn := NewConnection()
+	conn.Edges = edges
+	conn.PageInfo = PageInfo{
+		StartCursor:     firstEdgeCursor,
+		EndCursor:       lastEdgeCursor,
+		HasPreviousPage: hasPreviousPage,
+		HasNextPage:     hasNextPage,
+	}
+	if args.First == -1 {
+		conn.TotalCount = &totalCount
+	}
+	return conn, nil
+}
+
+// Creates the cursor string from the number of items taken from each source.
+func PositionsToCursor(positions []int) ConnectionCursor {
+	str := []string{}
+	for _, position := range positions {
+		str = append(str, strconv.Itoa(position))
+	}
+	return ConnectionCursor(base64.StdEncoding.EncodeToString([]byte(MERGE_PREFIX + strings.Join(str, ","))))
+}
+
+// Re-derives the number of items taken from each source from the cursor string.
+func CursorToPositions(cursor ConnectionCursor) ([]int, error) {
+	b, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil || !strings.HasPrefix(string(b), MERGE_PREFIX) {
+		return nil, errors.New("Invalid cursor")
+	}
+	positions := []int{}
+	for _, str := range strings.Split(string(b[len(MERGE_PREFIX):]), ",") {
+		position, err := strconv.Atoi(str)
+		if err != nil || position < 0 {
+			return nil, errors.New("Invalid cursor")
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// The items fetched from one source and not merged yet.
+type mergeSource struct {
+	index int
+	items []interface{}
+}
+
+// A heap of sources ordered by their next item; equivalent items are taken
+// from the sources in the order they were given.
+type mergeHeap struct {
+	sources []*mergeSource
+	compare CompareFn
+}
+
+func (h *mergeHeap) Len() int {
+	return len(h.sources)
+}
+
+func (h *mergeHeap) Less(i, j int) bool {
+	c := h.compare(h.sources[i].items[0], h.sources[j].items[0])
+	if c == 0 {
+		return h.sources[i].index < h.sources[j].index
+	}
+	return c < 0
+}
+
+func (h *mergeHeap) Swap(i, j int) {
+	h.sources[i], h.sources[j] = h.sources[j], h.sources[i]
+}
+
+func (h *mergeHeap) Push(x interface{}) {
+	h.sources = append(h.sources, x.(*mergeSource))
+}
+
+func (h *mergeHeap) Pop() interface{} {
+	last := h.sources[len(h.sources)-1]
+	h.sources = h.sources[:len(h.sources)-1]
+	return last
+}
@@ -0,0 +1,202 @@
+package relay_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+func mergeTestCompare(a, b interface{}) int {
+	return a.(int) - b.(int)
+}
+
+func mergeTestNodes(conn *relay.Connection) []interface{} {
+	nodes := []interface{}{}
+	for _, edge := range conn.Edges {
+		nodes = append(nodes, edge.Node)
+	}
+	return nodes
+}
+
+func mergeTestSources() []relay.Paginator {
+	return []relay.Paginator{
+		&paginatorTestPaginator{data: []interface{}{1, 4, 4, 7}},
+		&paginatorTestPaginator{data: []interface{}{2, 4, 8}},
+		&paginatorTestPaginator{data: []interface{}{}},
+		&paginatorTestPaginator{data: []interface{}{3, 5, 6, 9}},
+	}
+}
+
+func TestPositionsToCursor_RoundTripsPositions(t *testing.T) {
+	positions, err := relay.CursorToPositions(relay.PositionsToCursor([]int{3, 0, 12}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(positions, []int{3, 0, 12}) {
+		t.Fatalf("wrong positions, got: %v", positions)
+	}
+}
+func TestCursorToPositions_RejectsOffsetCursors(t *testing.T) {
+	_, err := relay.CursorToPositions(relay.OffsetToCursor(1))
+	if err == nil {
+		t.Fatalf("expected error for offset cursor")
+	}
+}
+func TestMergeConnections_MergesAllSourcesInOrder(t *testing.T) {
+	conn, err := relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []interface{}{1, 2, 3, 4, 4, 4, 5, 6, 7, 8, 9}
+	if nodes := mergeTestNodes(conn); !reflect.DeepEqual(nodes, expected) {
+		t.Fatalf("wrong nodes, expected: %v, got: %v", expected, nodes)
+	}
+	if conn.PageInfo.HasNextPage || conn.PageInfo.HasPreviousPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+	if conn.TotalCount == nil || *conn.TotalCount != 11 {
+		t.Fatalf("wrong total count, got: %v", conn.TotalCount)
+	}
+
+	conn, err = relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conn.TotalCount != nil {
+		t.Fatalf("expected unknown total count, got: %v", *conn.TotalCount)
+	}
+}
+func TestMergeConnections_PagesThroughTheUnion(t *testing.T) {
+	sources := mergeTestSources()
+	nodes := []interface{}{}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	})
+	for {
+		conn, err := relay.MergeConnections(context.Background(), sources, mergeTestCompare, args)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nodes = append(nodes, mergeTestNodes(conn)...)
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		args = relay.NewConnectionArguments(map[string]interface{}{
+			"first": 2,
+			"after": string(conn.PageInfo.EndCursor),
+		})
+	}
+	expected := []interface{}{1, 2, 3, 4, 4, 4, 5, 6, 7, 8, 9}
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Fatalf("wrong nodes, expected: %v, got: %v", expected, nodes)
+	}
+
+	// each page fetches one extra item from every non-exhausted source
+	first := sources[0].(*paginatorTestPaginator)
+	if !reflect.DeepEqual(first.fetches[0], paginatorTestFetch{0, 3}) {
+		t.Fatalf("wrong fetch, got: %v", first.fetches)
+	}
+}
+func TestMergeConnections_CursorRecordsEachSourcePosition(t *testing.T) {
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 5,
+	})
+	conn, err := relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	positions, err := relay.CursorToPositions(conn.PageInfo.EndCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 1 and both 4s from the first source, 2 from the second, 3 from the fourth
+	if !reflect.DeepEqual(positions, []int{3, 1, 0, 1}) {
+		t.Fatalf("wrong positions, got: %v", positions)
+	}
+}
+func TestMergeConnections_BidirectionalPageInfo(t *testing.T) {
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(relay.PositionsToCursor([]int{1, 0, 0, 0})),
+	})
+	conn, err := relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, args, relay.ConnectionOptions{
+		BidirectionalPageInfo: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(mergeTestNodes(conn), []interface{}{2, 3}) {
+		t.Fatalf("wrong nodes, got: %v", mergeTestNodes(conn))
+	}
+	if !conn.PageInfo.HasPreviousPage || !conn.PageInfo.HasNextPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+}
+func TestMergeConnections_ReturnsErrors(t *testing.T) {
+	_, err := relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(map[string]interface{}{
+		"last": 2,
+	}))
+	if err == nil {
+		t.Fatalf("expected error for backward pagination")
+	}
+
+	_, err = relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(map[string]interface{}{
+		"after": string(relay.PositionsToCursor([]int{1, 0})),
+	}))
+	if err == nil {
+		t.Fatalf("expected error for a cursor of other sources")
+	}
+
+	sources := []relay.Paginator{
+		&paginatorTestPaginator{data: []interface{}{1}, err: errors.New("connection refused")},
+	}
+	_, err = relay.MergeConnections(context.Background(), sources, mergeTestCompare, relay.NewConnectionArguments(nil))
+	if err == nil || err.Error() != "connection refused" {
+		t.Fatalf("expected source error, got: %v", err)
+	}
+}
+func TestMergeConnections_EncryptsCursorsWithPayloadCodec(t *testing.T) {
+	options := relay.ConnectionOptions{
+		CursorCodec: relay.NewEncryptedCursorCodec(nil, encryptionTestKeyRing(t, encryptionTestNewKey)),
+	}
+	conn, err := relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	}), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := relay.CursorToPositions(conn.PageInfo.EndCursor); err == nil {
+		t.Fatalf("expected cursor to be encrypted, got: %v", conn.PageInfo.EndCursor)
+	}
+
+	conn, err = relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(conn.PageInfo.EndCursor),
+	}), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(mergeTestNodes(conn), []interface{}{3, 4}) {
+		t.Fatalf("wrong nodes, got: %v", mergeTestNodes(conn))
+	}
+
+	_, err = relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(map[string]interface{}{
+		"after": string(relay.PositionsToCursor([]int{1, 0, 0, 0})),
+	}), options)
+	var decryptionErr *relay.DecryptionError
+	if !errors.As(err, &decryptionErr) {
+		t.Fatalf("expected *DecryptionError for plain cursor, got: %v", err)
+	}
+
+	_, err = relay.MergeConnections(context.Background(), mergeTestSources(), mergeTestCompare, relay.NewConnectionArguments(nil), relay.ConnectionOptions{
+		CursorCodec: keysetTestOffsetCodec{},
+	})
+	if err == nil {
+		t.Fatalf("expected error for a codec that can only encode offsets")
+	}
+}
@@ -12,13 +12,28 @@ import (
 type NodeDefinitions struct {
 	NodeInterface *graphql.Interface
 	NodeField     *graphql.Field
+	NodesField    *graphql.Field
+
+	config NodeDefinitionsConfig
 }
 
 type NodeDefinitionsConfig struct {
 	IDFetcher   IDFetcherFn
 	TypeResolve graphql.ResolveTypeFn
+
+	// Fetches several objects at once; when set, the `node` and `nodes`
+	// fields batch the IDs of a request instead of calling IDFetcher for
+	// each one.
+	BatchIDFetcher BatchIDFetcherFn
+	// Decodes the type of the IDs to batch them by type, Base64GlobalIDCodec
+	// by default.
+	GlobalIDCodec GlobalIDCodec
 }
 type IDFetcherFn func(id string, info graphql.ResolveInfo, ctx context.Context) (interface{}, error)
+
+// Returns the objects of the IDs, all of the same type, in the same order:
+// nil for missing ones.
+type BatchIDFetcherFn func(ids []string, ctx context.Context) ([]interface{}, error)
 type GlobalIDFetcherFn func(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (string, error)
 
 /*
@@ -30,6 +45,10 @@ type GlobalIDFetcherFn func(obj interface{}, info graphql.ResolveInfo, ctx conte
  If the typeResolver is omitted, object resolution on the interface will be
  handled with the `isTypeOf` method on object types, as with any GraphQL
 interface without a provided `resolveType` method.
+
+ It also constructs a field config for a `nodes` root field, fetching a list
+ of objects given their IDs. If a batch fetcher is given, both fields are
+ resolved through the request loaders of LoadNode.
 */
 func NewNodeDefinitions(config NodeDefinitionsConfig) *NodeDefinitions {
 	nodeInterface := graphql.NewInterface(graphql.InterfaceConfig{
@@ -44,7 +63,11 @@ func NewNodeDefinitions(config NodeDefinitionsConfig) *NodeDefinitions {
 		ResolveType: config.TypeResolve,
 	})
 
-	nodeField := &graphql.Field{
+	nodeDefinitions := &NodeDefinitions{
+		NodeInterface: nodeInterface,
+		config:        config,
+	}
+	nodeDefinitions.NodeField = &graphql.Field{
 		Name:        "Node",
 		Description: "Fetches an object given its ID",
 		Type:        nodeInterface,
@@ -55,20 +78,106 @@ func NewNodeDefinitions(config NodeDefinitionsConfig) *NodeDefinitions {
 			},
 		},
 		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-			if config.IDFetcher == nil {
-				return nil, nil
-			}
 			id := ""
 			if iid, ok := p.Args["id"]; ok {
 				id = fmt.Sprintf("%v", iid)
 			}
+			if config.BatchIDFetcher != nil {
+				return nodeDefinitions.LoadNode(p.Context, id), nil
+			}
+			if config.IDFetcher == nil {
+				return nil, nil
+			}
 			return config.IDFetcher(id, p.Info, p.Context)
 		},
 	}
-	return &NodeDefinitions{
-		NodeInterface: nodeInterface,
-		NodeField:     nodeField,
+	nodeDefinitions.NodesField = &graphql.Field{
+		Name:        "Nodes",
+		Description: "Fetches objects given their IDs",
+		Type:        graphql.NewNonNull(graphql.NewList(nodeInterface)),
+		Args: graphql.FieldConfigArgument{
+			"ids": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID))),
+				Description: "The IDs of objects",
+			},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			ids, _ := p.Args["ids"].([]interface{})
+			nodes := []interface{}{}
+			for _, iid := range ids {
+				id := fmt.Sprintf("%v", iid)
+				switch {
+				case config.BatchIDFetcher != nil:
+					thunk := nodeDefinitions.LoadNode(p.Context, id)
+					nodes = append(nodes, func() (interface{}, error) {
+						node, err := thunk()
+						if err != nil {
+							return nil, nil
+						}
+						return node, nil
+					})
+				case config.IDFetcher != nil:
+					node, err := config.IDFetcher(id, p.Info, p.Context)
+					if err != nil {
+						node = nil
+					}
+					nodes = append(nodes, node)
+				default:
+					nodes = append(nodes, nil)
+				}
+			}
+			return nodes, nil
+		},
 	}
+	return nodeDefinitions
+}
+
+/*
+Queues the global ID in the loader of the request (see WithLoaders), and
+returns a thunk resolving to its object, so that the `node` and `nodes` fields
+and the nodes of connections (see ConnectionConfig.NodeLoader) are fetched
+together.
+
+The IDs of each type are fetched with a call of the batch fetcher, or else one
+by one with the IDFetcher, called with an empty ResolveInfo.
+*/
+func (d *NodeDefinitions) LoadNode(ctx context.Context, id string) func() (interface{}, error) {
+	key := nodeLoaderKey{definitions: d}
+	if resolvedID, err := d.globalIDCodec().FromGlobalID(id); err == nil {
+		key.ttype = resolvedID.Type
+	}
+	return LoaderFromContext(ctx, key, d.fetchNodes).Load(ctx, id)
+}
+
+// Identifies the loader of the IDs of a type.
+type nodeLoaderKey struct {
+	definitions *NodeDefinitions
+	ttype       string
+}
+
+func (d *NodeDefinitions) globalIDCodec() GlobalIDCodec {
+	if d.config.GlobalIDCodec == nil {
+		return Base64GlobalIDCodec{}
+	}
+	return d.config.GlobalIDCodec
+}
+
+func (d *NodeDefinitions) fetchNodes(ctx context.Context, ids []string) ([]interface{}, error) {
+	if d.config.BatchIDFetcher != nil {
+		return d.config.BatchIDFetcher(ids, ctx)
+	}
+	nodes := []interface{}{}
+	for _, id := range ids {
+		var node interface{}
+		if d.config.IDFetcher != nil {
+			var err error
+			if node, err = d.config.IDFetcher(id, graphql.ResolveInfo{}, ctx); err != nil {
+				node = err
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
 }
 
 type ResolvedGlobalID struct {
@@ -89,6 +198,10 @@ func ToGlobalID(ttype string, id string) string {
 /*
 Takes the "global ID" created by toGlobalID, and returns the type name and ID
 used to create it.
+
+Only the first colon separates the type name, which cannot contain one, from
+the ID: IDs containing colons or any other bytes round-trip exactly. The
+format is unchanged, so every global ID issued so far still decodes.
 */
 func FromGlobalID(globalID string) *ResolvedGlobalID {
 	strID := ""
@@ -96,7 +209,7 @@ func FromGlobalID(globalID string) *ResolvedGlobalID {
 	if err == nil {
 		strID = string(b)
 	}
-	tokens := strings.Split(strID, ":")
+	tokens := strings.SplitN(strID, ":", 2)
 	if len(tokens) < 2 {
 		return nil
 	}
@@ -106,6 +219,30 @@ func FromGlobalID(globalID string) *ResolvedGlobalID {
 	}
 }
 
+/*
+Translates between a type name and type-specific ID, and the opaque global ID
+handed to clients.
+*/
+type GlobalIDCodec interface {
+	ToGlobalID(ttype string, id string) string
+	FromGlobalID(globalID string) (*ResolvedGlobalID, error)
+}
+
+// A GlobalIDCodec using the base64 format of `ToGlobalID` and `FromGlobalID`.
+type Base64GlobalIDCodec struct{}
+
+func (c Base64GlobalIDCodec) ToGlobalID(ttype string, id string) string {
+	return ToGlobalID(ttype, id)
+}
+
+func (c Base64GlobalIDCodec) FromGlobalID(globalID string) (*ResolvedGlobalID, error) {
+	resolvedGlobalID, err := DecodeGlobalID(globalID)
+	if err != nil {
+		return nil, err
+	}
+	return &resolvedGlobalID, nil
+}
+
 /*
 Creates the configuration for an id field on a node, using `toGlobalId` to
 construct the ID from the provided typename. The type-specific ID is fetcher
@@ -113,6 +250,14 @@ by calling idFetcher on the object, or if not provided, by accessing the `id`
 property on the object.
 */
 func GlobalIDField(typeName string, idFetcher GlobalIDFetcherFn) *graphql.Field {
+	return GlobalIDFieldWithCodec(typeName, idFetcher, Base64GlobalIDCodec{})
+}
+
+/*
+Like `GlobalIDField`, but constructs the ID with the given codec, e.g. an
+`EncryptedGlobalIDCodec`.
+*/
+func GlobalIDFieldWithCodec(typeName string, idFetcher GlobalIDFetcherFn, codec GlobalIDCodec) *graphql.Field {
 	return &graphql.Field{
 		Name:        "id",
 		Description: "The ID of an object",
@@ -137,7 +282,7 @@ func GlobalIDField(typeName string, idFetcher GlobalIDFetcherFn) *graphql.Field
 					}
 				}
 			}
-			globalID := ToGlobalID(typeName, id)
+			globalID := codec.ToGlobalID(typeName, id)
 			return globalID, nil
 		},
 	}
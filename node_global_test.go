@@ -182,3 +182,36 @@ func TestGlobalIDFields_RefetchesTheIDs(t *testing.T) {
 		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
 	}
 }
+func TestFromGlobalID_RoundTripsIDsWithColons(t *testing.T) {
+	ids := []string{
+		"urn:isbn:123",
+		"tenant:2015:42",
+		":",
+		"",
+		"\x00\xff:\n",
+	}
+	for _, id := range ids {
+		expected := &relay.ResolvedGlobalID{
+			Type: "Book",
+			ID:   id,
+		}
+		resolvedID := relay.FromGlobalID(relay.ToGlobalID("Book", id))
+		if !reflect.DeepEqual(resolvedID, expected) {
+			t.Fatalf("wrong resolved ID for %q, expected: %+v, got: %+v", id, expected, resolvedID)
+		}
+	}
+}
+func TestFromGlobalID_DecodesPreviouslyIssuedIDs(t *testing.T) {
+	// "VXNlcjox" is "User:1", as issued by earlier versions of ToGlobalID
+	expected := &relay.ResolvedGlobalID{
+		Type: "User",
+		ID:   "1",
+	}
+	resolvedID := relay.FromGlobalID("VXNlcjox")
+	if !reflect.DeepEqual(resolvedID, expected) {
+		t.Fatalf("wrong resolved ID, expected: %+v, got: %+v", expected, resolvedID)
+	}
+	if resolvedID := relay.FromGlobalID("VXNlcg=="); resolvedID != nil {
+		t.Fatalf("expected nil for an ID without separator, got: %+v", resolvedID)
+	}
+}
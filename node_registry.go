@@ -0,0 +1,167 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"golang.org/x/net/context"
+)
+
+/*
+An object type implementing the `Node` interface, registered in a
+NodeRegistry.
+
+`Fetch` is called with the type-specific ID of the global ID. `IsTypeOf`
+tells whether a value resolves to the type; RegisterNodeType derives it from
+the Go type returned by the fetcher. `Name` defaults to the name of `Type`.
+*/
+type NodeTypeConfig struct {
+	Type     *graphql.Object
+	Name     string
+	Fetch    IDFetcherFn
+	IsTypeOf func(value interface{}) bool
+}
+
+type NodeRegistryConfig struct {
+	// The codec of the global IDs, Base64GlobalIDCodec by default.
+	GlobalIDCodec GlobalIDCodec
+}
+
+/*
+Dispatches the `node` field and the `Node` interface to the registered object
+types, instead of switching over type names and Go types by hand:
+
+	registry := relay.NewNodeRegistry(relay.NodeRegistryConfig{})
+	shipType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Ship",
+		Fields: graphql.Fields{
+			"id": registry.GlobalIDField("Ship", nil),
+		},
+		Interfaces: []*graphql.Interface{registry.NodeInterface},
+	})
+	relay.RegisterNodeType(registry, shipType, fetchShip)
+
+The embedded NodeDefinitions hold the interface and field to add to the
+schema. Types can be registered after the interface is used, but before the
+schema is queried.
+*/
+type NodeRegistry struct {
+	*NodeDefinitions
+
+	codec GlobalIDCodec
+	mu    sync.RWMutex
+	types map[string]*NodeTypeConfig
+	order []*NodeTypeConfig
+}
+
+func NewNodeRegistry(config NodeRegistryConfig) *NodeRegistry {
+	registry := &NodeRegistry{
+		codec: config.GlobalIDCodec,
+		types: map[string]*NodeTypeConfig{},
+	}
+	if registry.codec == nil {
+		registry.codec = Base64GlobalIDCodec{}
+	}
+	registry.NodeDefinitions = NewNodeDefinitions(NodeDefinitionsConfig{
+		IDFetcher:     registry.fetch,
+		TypeResolve:   registry.resolveType,
+		GlobalIDCodec: registry.codec,
+	})
+	return registry
+}
+
+// Adds a node type; returns an error if its name is already registered.
+func (r *NodeRegistry) Register(config NodeTypeConfig) error {
+	if config.Type == nil {
+		return errors.New("Node type must have an object type")
+	}
+	if config.Fetch == nil || config.IsTypeOf == nil {
+		return fmt.Errorf("Node type %v must have a fetcher and an IsTypeOf function", config.Type.Name())
+	}
+	if config.Name == "" {
+		config.Name = config.Type.Name()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.types[config.Name]; ok {
+		return fmt.Errorf("Node type %v is already registered", config.Name)
+	}
+	r.types[config.Name] = &config
+	r.order = append(r.order, &config)
+	return nil
+}
+
+/*
+Registers a node type whose fetcher returns values of type T, so that values
+of type T resolve to the object type. A fetcher returning a nil pointer
+resolves the `node` field to null.
+*/
+func RegisterNodeType[T any](r *NodeRegistry, objectType *graphql.Object, fetch func(id string, info graphql.ResolveInfo, ctx context.Context) (T, error)) error {
+	return r.Register(NodeTypeConfig{
+		Type: objectType,
+		Fetch: func(id string, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+			node, err := fetch(id, info, ctx)
+			if err != nil || isNilValue(node) {
+				return nil, err
+			}
+			return node, nil
+		},
+		IsTypeOf: func(value interface{}) bool {
+			_, ok := value.(T)
+			return ok
+		},
+	})
+}
+
+// Like GlobalIDFieldWithCodec, with the codec of the registry.
+func (r *NodeRegistry) GlobalIDField(typeName string, idFetcher GlobalIDFetcherFn) *graphql.Field {
+	return GlobalIDFieldWithCodec(typeName, idFetcher, r.codec)
+}
+
+// Returns the global ID of an object of a registered type.
+func (r *NodeRegistry) ToGlobalID(typeName string, id string) string {
+	return r.codec.ToGlobalID(typeName, id)
+}
+
+// Fetches the object of a global ID; nil for invalid IDs and unknown types.
+func (r *NodeRegistry) fetch(globalID string, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+	resolvedID, err := r.codec.FromGlobalID(globalID)
+	if err != nil {
+		return nil, nil
+	}
+	r.mu.RLock()
+	config, ok := r.types[resolvedID.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return config.Fetch(resolvedID.ID, info, ctx)
+}
+
+// Returns the first registered object type the value is of.
+func (r *NodeRegistry) resolveType(p graphql.ResolveTypeParams) *graphql.Object {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, config := range r.order {
+		if config.IsTypeOf(p.Value) {
+			return config.Type
+		}
+	}
+	return nil
+}
+
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package relay_test
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+var nodeRegistryTestRegistry = relay.NewNodeRegistry(relay.NodeRegistryConfig{})
+var nodeRegistryTestSchema graphql.Schema
+
+func init() {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": nodeRegistryTestRegistry.GlobalIDField("User", nil),
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+		Interfaces: []*graphql.Interface{nodeRegistryTestRegistry.NodeInterface},
+	})
+	photoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Photo",
+		Fields: graphql.Fields{
+			"id": nodeRegistryTestRegistry.GlobalIDField("Photo", nil),
+			"width": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+		Interfaces: []*graphql.Interface{nodeRegistryTestRegistry.NodeInterface},
+	})
+	err := relay.RegisterNodeType(nodeRegistryTestRegistry, userType, func(id string, info graphql.ResolveInfo, ctx context.Context) (*user, error) {
+		return nodeTestUserData[id], nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	err = relay.RegisterNodeType(nodeRegistryTestRegistry, photoType, func(id string, info graphql.ResolveInfo, ctx context.Context) (*photo, error) {
+		return nodeTestPhotoData[id], nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	nodeRegistryTestSchema, err = graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"node": nodeRegistryTestRegistry.NodeField,
+			},
+		}),
+		Types: []graphql.Type{userType, photoType},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestNodeRegistry_DispatchesNodesToTheirTypes(t *testing.T) {
+	query := `{
+      user: node(id: "` + relay.ToGlobalID("User", "1") + `") {
+        id
+        ... on User {
+          name
+        }
+      }
+      photo: node(id: "` + relay.ToGlobalID("Photo", "4") + `") {
+        id
+        ... on Photo {
+          width
+        }
+      }
+    }`
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"user": map[string]interface{}{
+				"id":   relay.ToGlobalID("User", "1"),
+				"name": "John Doe",
+			},
+			"photo": map[string]interface{}{
+				"id":    relay.ToGlobalID("Photo", "4"),
+				"width": 400,
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        nodeRegistryTestSchema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+func TestNodeRegistry_ReturnsNullForUnknownNodes(t *testing.T) {
+	ids := []string{
+		relay.ToGlobalID("User", "3"),
+		relay.ToGlobalID("Comment", "1"),
+		"invalid",
+	}
+	for _, id := range ids {
+		result := graphql.Do(graphql.Params{
+			Schema:        nodeRegistryTestSchema,
+			RequestString: `{ node(id: "` + id + `") { id } }`,
+		})
+		expected := &graphql.Result{
+			Data: map[string]interface{}{
+				"node": nil,
+			},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("wrong result for %v, graphql result diff: %v", id, testutil.Diff(expected, result))
+		}
+	}
+}
+func TestNodeRegistry_RejectsDuplicateTypes(t *testing.T) {
+	registry := relay.NewNodeRegistry(relay.NodeRegistryConfig{})
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": registry.GlobalIDField("User", nil),
+		},
+	})
+	fetch := func(id string, info graphql.ResolveInfo, ctx context.Context) (*user, error) {
+		i, _ := strconv.Atoi(id)
+		return &user{ID: i}, nil
+	}
+	if err := relay.RegisterNodeType(registry, userType, fetch); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := relay.RegisterNodeType(registry, userType, fetch); err == nil {
+		t.Fatalf("expected error for duplicate node type")
+	}
+	if err := registry.Register(relay.NodeTypeConfig{Type: userType, Name: "Member"}); err == nil {
+		t.Fatalf("expected error for node type without a fetcher")
+	}
+}
@@ -393,3 +393,127 @@ func TestNodeInterfaceAndFields_CorrectlyIntrospects_HasCorrectNodeRootField(t *
 		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
 	}
 }
+
+func nodeTestNodesSchema(t *testing.T, nodeDefinitions *relay.NodeDefinitions, types ...graphql.Type) graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"nodes": nodeDefinitions.NodesField,
+			},
+		}),
+		Types: types,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return schema
+}
+
+func TestNodesField_ReturnsNodesInOrder(t *testing.T) {
+	query := `{
+        nodes(ids: ["4", "1", "5", "2"]) {
+          id
+        }
+      }`
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"nodes": []interface{}{
+				map[string]interface{}{
+					"id": "4",
+				},
+				map[string]interface{}{
+					"id": "1",
+				},
+				nil,
+				map[string]interface{}{
+					"id": "2",
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        nodeTestNodesSchema(t, nodeTestDef, nodeTestUserType, nodeTestPhotoType),
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+func TestNodesField_BatchesIDsByType(t *testing.T) {
+	batches := [][]string{}
+	var userType, photoType *graphql.Object
+	nodeDefinitions := relay.NewNodeDefinitions(relay.NodeDefinitionsConfig{
+		BatchIDFetcher: func(ids []string, ctx context.Context) ([]interface{}, error) {
+			batches = append(batches, ids)
+			nodes := []interface{}{}
+			for _, id := range ids {
+				resolvedID := relay.FromGlobalID(id)
+				switch {
+				case resolvedID.ID == "2":
+					nodes = append(nodes, errors.New("Unauthorized"))
+				case resolvedID.Type == "User":
+					nodes = append(nodes, nodeTestUserData[resolvedID.ID])
+				case resolvedID.Type == "Photo":
+					nodes = append(nodes, nodeTestPhotoData[resolvedID.ID])
+				}
+			}
+			return nodes, nil
+		},
+		TypeResolve: func(p graphql.ResolveTypeParams) *graphql.Object {
+			if _, ok := p.Value.(*user); ok {
+				return userType
+			}
+			return photoType
+		},
+	})
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+		},
+		Interfaces: []*graphql.Interface{nodeDefinitions.NodeInterface},
+	})
+	photoType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Photo",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+			},
+		},
+		Interfaces: []*graphql.Interface{nodeDefinitions.NodeInterface},
+	})
+	query := `{
+        nodes(ids: ["VXNlcjox", "UGhvdG86Mw==", "VXNlcjoy", "UGhvdG86NQ=="]) {
+          id
+        }
+      }`
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"nodes": []interface{}{
+				map[string]interface{}{
+					"id": "1",
+				},
+				map[string]interface{}{
+					"id": "3",
+				},
+				nil,
+				nil,
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        nodeTestNodesSchema(t, nodeDefinitions, userType, photoType),
+		RequestString: query,
+		Context:       relay.WithLoaders(context.Background()),
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+	expectedBatches := [][]string{{"VXNlcjox", "VXNlcjoy"}, {"UGhvdG86Mw==", "UGhvdG86NQ=="}}
+	if !reflect.DeepEqual(batches, expectedBatches) {
+		t.Fatalf("wrong batches, expected: %v, got: %v", expectedBatches, batches)
+	}
+}
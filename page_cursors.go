@@ -0,0 +1,103 @@
+package relay
+
+// The number of pages listed by PageCursors, counting `first` and `last`.
+const maxPageCursors = 5
+
+// A cursor to pass as `after`, with `first` set to the page size, to get a page.
+// The cursor of the first page is empty: it is got without `after`.
+type PageCursor struct {
+	Cursor    ConnectionCursor `json:"cursor"`
+	Page      int              `json:"page"`
+	IsCurrent bool             `json:"isCurrent"`
+}
+
+/*
+Cursors to the pages of a connection, for numbered pagination
+("1 … 4 5 6 … 10").
+
+`Around` lists the pages around the current one. `First` and `Last` are set
+when the first and last pages are not in `Around`, and `Previous` when there
+is a page before the current one.
+*/
+type PageCursors struct {
+	First    *PageCursor   `json:"first"`
+	Around   []*PageCursor `json:"around"`
+	Last     *PageCursor   `json:"last"`
+	Previous *PageCursor   `json:"previous"`
+}
+
+/*
+Returns the cursors to the pages of a connection of `totalCount` items split
+in pages of `perPage` items, `page` being the current one, counting from 1.
+*/
+func NewPageCursors(page int, perPage int, totalCount int, opts ...ConnectionOptions) *PageCursors {
+	codec := mergeConnectionOptions(opts).cursorCodec()
+	pageCursor := func(p int) *PageCursor {
+		cursor := &PageCursor{
+			Page:      p,
+			IsCurrent: p == page,
+		}
+		if p > 1 {
+			cursor.Cursor = codec.EncodeCursor((p-1)*perPage - 1)
+		}
+		return cursor
+	}
+	pageRange := func(from, to int) []*PageCursor {
+		cursors := []*PageCursor{}
+		for p := from; p <= to; p++ {
+			cursors = append(cursors, pageCursor(p))
+		}
+		return cursors
+	}
+
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (totalCount + perPage - 1) / perPage
+	}
+	cursors := &PageCursors{}
+	switch {
+	case totalPages == 0:
+		cursors.Around = pageRange(1, 1)
+	case totalPages <= maxPageCursors:
+		cursors.Around = pageRange(1, totalPages)
+	case page <= maxPageCursors/2+1:
+		cursors.Around = pageRange(1, maxPageCursors-1)
+		cursors.Last = pageCursor(totalPages)
+	case page >= totalPages-maxPageCursors/2:
+		cursors.First = pageCursor(1)
+		cursors.Around = pageRange(totalPages-maxPageCursors+2, totalPages)
+	default:
+		offset := (maxPageCursors - 3) / 2
+		cursors.First = pageCursor(1)
+		cursors.Around = pageRange(page-offset, page+offset)
+		cursors.Last = pageCursor(totalPages)
+	}
+	if page > 1 {
+		cursors.Previous = pageCursor(page - 1)
+	}
+	return cursors
+}
+
+// Returns the connection arguments to get a page of `perPage` items,
+// counting pages from 1.
+func PageToConnectionArguments(page int, perPage int, opts ...ConnectionOptions) ConnectionArguments {
+	args := NewConnectionArguments(nil)
+	args.First = perPage
+	if page > 1 {
+		args.After = mergeConnectionOptions(opts).cursorCodec().EncodeCursor((page-1)*perPage - 1)
+	}
+	return args
+}
+
+// Returns the page cursors of the window, using `first` or `last` as the page
+// size; nil if neither is given.
+func (w arraySliceWindow) pageCursors(args ConnectionArguments, meta ArraySliceMetaInfo, options ConnectionOptions) *PageCursors {
+	perPage := args.First
+	if perPage == -1 {
+		perPage = args.Last
+	}
+	if perPage <= 0 {
+		return nil
+	}
+	return NewPageCursors(w.startOffset/perPage+1, perPage, meta.ArrayLength, options)
+}
@@ -0,0 +1,256 @@
+package relay_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+)
+
+// pageCursorsTestPages lists the page numbers of the cursors, marking the current one.
+func pageCursorsTestPages(cursors *relay.PageCursors) map[string]interface{} {
+	page := func(cursor *relay.PageCursor) interface{} {
+		if cursor == nil {
+			return nil
+		}
+		if cursor.IsCurrent {
+			return []int{cursor.Page}
+		}
+		return cursor.Page
+	}
+	around := []interface{}{}
+	for _, cursor := range cursors.Around {
+		around = append(around, page(cursor))
+	}
+	return map[string]interface{}{
+		"first":    page(cursors.First),
+		"around":   around,
+		"last":     page(cursors.Last),
+		"previous": page(cursors.Previous),
+	}
+}
+
+func TestNewPageCursors_ListsThePagesAroundTheCurrentOne(t *testing.T) {
+	tests := []struct {
+		page       int
+		totalCount int
+		expected   map[string]interface{}
+	}{
+		{1, 0, map[string]interface{}{
+			"first": nil, "around": []interface{}{[]int{1}}, "last": nil, "previous": nil,
+		}},
+		{2, 25, map[string]interface{}{
+			"first": nil, "around": []interface{}{1, []int{2}, 3}, "last": nil, "previous": 1,
+		}},
+		{2, 100, map[string]interface{}{
+			"first": nil, "around": []interface{}{1, []int{2}, 3, 4}, "last": 10, "previous": 1,
+		}},
+		{5, 100, map[string]interface{}{
+			"first": 1, "around": []interface{}{4, []int{5}, 6}, "last": 10, "previous": 4,
+		}},
+		{9, 100, map[string]interface{}{
+			"first": 1, "around": []interface{}{7, 8, []int{9}, 10}, "last": nil, "previous": 8,
+		}},
+	}
+	for _, test := range tests {
+		pages := pageCursorsTestPages(relay.NewPageCursors(test.page, 10, test.totalCount))
+		if !reflect.DeepEqual(pages, test.expected) {
+			t.Fatalf("wrong pages for page %v of %v items, expected: %v, got: %v", test.page, test.totalCount, test.expected, pages)
+		}
+	}
+}
+func TestNewPageCursors_CursorsGetTheirPage(t *testing.T) {
+	data := []interface{}{}
+	for i := 0; i < 25; i++ {
+		data = append(data, i)
+	}
+	cursors := relay.NewPageCursors(1, 10, len(data))
+	for _, cursor := range cursors.Around {
+		args := relay.NewConnectionArguments(map[string]interface{}{
+			"first": 10,
+			"after": string(cursor.Cursor),
+		})
+		conn := relay.ConnectionFromArray(data, args)
+		if conn.Edges[0].Node != (cursor.Page-1)*10 {
+			t.Fatalf("wrong first node for page %v, got: %v", cursor.Page, conn.Edges[0].Node)
+		}
+		expected := relay.PageToConnectionArguments(cursor.Page, 10)
+		if !reflect.DeepEqual(relay.ConnectionFromArray(data, expected), conn) {
+			t.Fatalf("wrong arguments for page %v, got: %+v", cursor.Page, expected)
+		}
+	}
+}
+func TestNewPageCursors_CursorsAreValidArguments(t *testing.T) {
+	data := []interface{}{}
+	for i := 0; i < 100; i++ {
+		data = append(data, i)
+	}
+	for page := 1; page <= 10; page++ {
+		cursors := relay.NewPageCursors(page, 10, len(data))
+		all := append([]*relay.PageCursor{cursors.First, cursors.Last, cursors.Previous}, cursors.Around...)
+		for _, cursor := range all {
+			if cursor == nil {
+				continue
+			}
+			args, err := relay.NewValidatedConnectionArguments(map[string]interface{}{
+				"first": 10,
+				"after": string(cursor.Cursor),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error for page %v: %v", cursor.Page, err)
+			}
+			conn := relay.ConnectionFromArray(data, args)
+			if conn.Edges[0].Node != (cursor.Page-1)*10 {
+				t.Fatalf("wrong first node for page %v, got: %v", cursor.Page, conn.Edges[0].Node)
+			}
+		}
+	}
+	if cursor := relay.NewPageCursors(2, 10, len(data)).Previous; cursor.Page != 1 || cursor.Cursor != "" {
+		t.Fatalf("expected the first page to have no cursor, got: %+v", cursor)
+	}
+}
+func TestConnectionFromArray_PageCursorsOption(t *testing.T) {
+	data := []interface{}{}
+	for i := 0; i < 100; i++ {
+		data = append(data, i)
+	}
+	options := relay.ConnectionOptions{
+		PageCursors: true,
+	}
+	conn := relay.ConnectionFromArray(data, relay.PageToConnectionArguments(5, 10), options)
+	expected := relay.NewPageCursors(5, 10, 100)
+	if !reflect.DeepEqual(conn.PageCursors, expected) {
+		t.Fatalf("wrong page cursors, diff: %v", testutil.Diff(expected, conn.PageCursors))
+	}
+
+	// a page past the end still links to the existing pages
+	conn = relay.ConnectionFromArray(data, relay.PageToConnectionArguments(12, 10), options)
+	if len(conn.Edges) != 0 || conn.PageCursors == nil || len(conn.PageCursors.Around) == 0 {
+		t.Fatalf("expected page cursors for an empty page, got: %+v", conn.PageCursors)
+	}
+	typed := relay.ConnectionFromSlice(data, relay.PageToConnectionArguments(12, 10), options)
+	if !reflect.DeepEqual(typed.PageCursors, conn.PageCursors) {
+		t.Fatalf("wrong typed page cursors, diff: %v", testutil.Diff(conn.PageCursors, typed.PageCursors))
+	}
+
+	conn = relay.ConnectionFromArray(data, relay.NewConnectionArguments(nil), options)
+	if conn.PageCursors != nil {
+		t.Fatalf("expected no page cursors without a page size, got: %+v", conn.PageCursors)
+	}
+	conn = relay.ConnectionFromArray(data, relay.PageToConnectionArguments(5, 10))
+	if conn.PageCursors != nil {
+		t.Fatalf("expected no page cursors without the option, got: %+v", conn.PageCursors)
+	}
+}
+func TestConnectionDefinitions_IncludesPageCursorsField(t *testing.T) {
+	letterType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Letter",
+		Fields: graphql.Fields{
+			"value": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+	connectionDef := relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name:        "Letter",
+		NodeType:    letterType,
+		PageCursors: true,
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"letters": &graphql.Field{
+					Type: connectionDef.ConnectionType,
+					Args: relay.NewConnectionArgs(graphql.FieldConfigArgument{
+						"page": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+					}),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						args := relay.PageToConnectionArguments(p.Args["page"].(int), 2)
+						return relay.ConnectionFromArray(arrayConnectionTestLetters, args, relay.ConnectionOptions{
+							PageCursors: true,
+						}), nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query := `
+      query LettersQuery {
+        letters(page: 2) {
+          edges {
+            node {
+              value
+            }
+          }
+          pageCursors {
+            first {
+              page
+            }
+            around {
+              page
+              isCurrent
+            }
+            previous {
+              cursor
+            }
+          }
+        }
+      }
+    `
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"letters": map[string]interface{}{
+				"edges": []interface{}{
+					map[string]interface{}{
+						"node": map[string]interface{}{
+							"value": "C",
+						},
+					},
+					map[string]interface{}{
+						"node": map[string]interface{}{
+							"value": "D",
+						},
+					},
+				},
+				"pageCursors": map[string]interface{}{
+					"first": nil,
+					"around": []interface{}{
+						map[string]interface{}{
+							"page":      1,
+							"isCurrent": false,
+						},
+						map[string]interface{}{
+							"page":      2,
+							"isCurrent": true,
+						},
+						map[string]interface{}{
+							"page":      3,
+							"isCurrent": false,
+						},
+					},
+					"previous": map[string]interface{}{
+						"cursor": nil,
+					},
+				},
+			},
+		},
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
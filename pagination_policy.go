@@ -0,0 +1,67 @@
+package relay
+
+import (
+	"errors"
+)
+
+// The page size given in `first` or `last` is larger than PaginationPolicy.MaxPageSize.
+var ErrPageSizeTooLarge = errors.New("exceeds the maximum page size")
+
+/*
+Limits the page sizes clients can request from a connection.
+
+When neither `first` nor `last` is given, `DefaultPageSize` items are
+returned: the last ones if only `before` is given, the first ones otherwise.
+`first` and `last` above `MaxPageSize` are clamped to it, or rejected with
+ErrPageSizeTooLarge if `RejectOverMax` is set. Zero disables either limit.
+*/
+type PaginationPolicy struct {
+	DefaultPageSize int  `json:"defaultPageSize"`
+	MaxPageSize     int  `json:"maxPageSize"`
+	RejectOverMax   bool `json:"rejectOverMax"`
+}
+
+/*
+Returns the arguments with the policy applied, or a *ConnectionArgumentError
+wrapping ErrPageSizeTooLarge if a page size is over the maximum and the
+policy rejects those.
+*/
+func (p *PaginationPolicy) Apply(args ConnectionArguments) (ConnectionArguments, error) {
+	if p == nil {
+		return args, nil
+	}
+	if p.DefaultPageSize > 0 && args.First == -1 && args.Last == -1 {
+		if args.Before != "" && args.After == "" {
+			args.Last = p.DefaultPageSize
+		} else {
+			args.First = p.DefaultPageSize
+		}
+	}
+	if p.MaxPageSize > 0 {
+		if args.First > p.MaxPageSize {
+			if p.RejectOverMax {
+				return args, &ConnectionArgumentError{Argument: "first", Value: args.First, Err: ErrPageSizeTooLarge}
+			}
+			args.First = p.MaxPageSize
+		}
+		if args.Last > p.MaxPageSize {
+			if p.RejectOverMax {
+				return args, &ConnectionArgumentError{Argument: "last", Value: args.Last, Err: ErrPageSizeTooLarge}
+			}
+			args.Last = p.MaxPageSize
+		}
+	}
+	return args, nil
+}
+
+// Applies the policy, clamping page sizes over the maximum even if the
+// policy would reject them. Used by the helpers that cannot return errors.
+func (p *PaginationPolicy) clamp(args ConnectionArguments) ConnectionArguments {
+	if p == nil {
+		return args
+	}
+	clamping := *p
+	clamping.RejectOverMax = false
+	args, _ = clamping.Apply(args)
+	return args
+}
@@ -0,0 +1,153 @@
+package relay_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+var paginationPolicyTestPolicy = &relay.PaginationPolicy{
+	DefaultPageSize: 2,
+	MaxPageSize:     3,
+}
+
+func TestPaginationPolicy_AppliesDefaultPageSize(t *testing.T) {
+	options := relay.ConnectionOptions{
+		PaginationPolicy: paginationPolicyTestPolicy,
+	}
+
+	args := relay.NewConnectionArguments(nil, options)
+	if args.First != 2 || args.Last != -1 {
+		t.Fatalf("wrong arguments, got: %+v", args)
+	}
+
+	args = relay.NewConnectionArguments(map[string]interface{}{
+		"before": "YXJyYXljb25uZWN0aW9uOjQ=",
+	}, options)
+	if args.First != -1 || args.Last != 2 {
+		t.Fatalf("expected default page size to apply to `last`, got: %+v", args)
+	}
+
+	result := relay.ConnectionFromArray(arrayConnectionTestLetters, relay.NewConnectionArguments(nil), options)
+	if len(result.Edges) != 2 || !result.PageInfo.HasNextPage {
+		t.Fatalf("wrong result, got: %v, %+v", result.Edges, result.PageInfo)
+	}
+}
+func TestPaginationPolicy_ClampsToMaxPageSize(t *testing.T) {
+	options := relay.ConnectionOptions{
+		PaginationPolicy: paginationPolicyTestPolicy,
+	}
+	filter := map[string]interface{}{
+		"first": 1000000,
+		"last":  1000000,
+	}
+
+	args := relay.NewConnectionArguments(filter, options)
+	if args.First != 3 || args.Last != 3 {
+		t.Fatalf("wrong arguments, got: %+v", args)
+	}
+
+	result := relay.ConnectionFromArray(arrayConnectionTestLetters, relay.NewConnectionArguments(filter), options)
+	if len(result.Edges) != 3 {
+		t.Fatalf("wrong result, got: %v", result.Edges)
+	}
+}
+func TestPaginationPolicy_OptionallyRejectsOverMax(t *testing.T) {
+	options := relay.ConnectionOptions{
+		PaginationPolicy: &relay.PaginationPolicy{
+			MaxPageSize:   3,
+			RejectOverMax: true,
+		},
+	}
+
+	_, err := relay.NewValidatedConnectionArguments(map[string]interface{}{
+		"last": 4,
+	}, options)
+	argErr, ok := err.(*relay.ConnectionArgumentError)
+	if !ok || argErr.Argument != "last" || !errors.Is(err, relay.ErrPageSizeTooLarge) {
+		t.Fatalf("expected ErrPageSizeTooLarge for `last`, got: %v", err)
+	}
+
+	args, err := relay.NewValidatedConnectionArguments(map[string]interface{}{
+		"first": 3,
+	}, options)
+	if err != nil || args.First != 3 {
+		t.Fatalf("unexpected result: %+v, %v", args, err)
+	}
+
+	// helpers that cannot return errors clamp instead
+	args = relay.NewConnectionArguments(map[string]interface{}{
+		"first": 4,
+	}, options)
+	if args.First != 3 {
+		t.Fatalf("wrong arguments, got: %+v", args)
+	}
+}
+func TestPaginationPolicy_RejectsOverMaxInPaginatorConnections(t *testing.T) {
+	paginator := &paginatorTestPaginator{data: arrayConnectionTestLetters}
+	options := relay.ConnectionOptions{
+		PaginationPolicy: &relay.PaginationPolicy{
+			MaxPageSize:   3,
+			RejectOverMax: true,
+		},
+	}
+
+	_, err := relay.ConnectionFromPaginator(context.Background(), paginator, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 4,
+	}), options)
+	var argErr *relay.ConnectionArgumentError
+	if !errors.As(err, &argErr) || argErr.Argument != "first" || !errors.Is(err, relay.ErrPageSizeTooLarge) {
+		t.Fatalf("expected ErrPageSizeTooLarge for `first`, got: %v", err)
+	}
+	if len(paginator.fetches) != 0 {
+		t.Fatalf("expected no fetch, got: %v", paginator.fetches)
+	}
+}
+func TestPaginationPolicy_RejectsOverMaxInSeq2Connections(t *testing.T) {
+	options := relay.ConnectionOptions{
+		PaginationPolicy: &relay.PaginationPolicy{
+			MaxPageSize:   3,
+			RejectOverMax: true,
+		},
+	}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"last": 4,
+	})
+
+	read := false
+	seq := func(yield func(interface{}, error) bool) {
+		read = true
+	}
+	_, err := relay.ConnectionFromSeq2(seq, args, options)
+	var argErr *relay.ConnectionArgumentError
+	if !errors.As(err, &argErr) || argErr.Argument != "last" || !errors.Is(err, relay.ErrPageSizeTooLarge) {
+		t.Fatalf("expected ErrPageSizeTooLarge for `last`, got: %v", err)
+	}
+	if read {
+		t.Fatalf("expected the sequence not to be read")
+	}
+
+	// ConnectionFromSeq cannot return errors, so it clamps instead
+	conn := relay.ConnectionFromSeq(slices.Values(arrayConnectionTestLetters), args, options)
+	if len(conn.Edges) != 3 {
+		t.Fatalf("wrong edges, got: %v", conn.Edges)
+	}
+}
+func TestPaginationPolicy_AppliesToKeysetConnections(t *testing.T) {
+	queries := []relay.KeysetQuery{}
+	fetch := keysetTestFetch(keysetTestRows, &queries)
+	options := relay.ConnectionOptions{
+		PaginationPolicy: paginationPolicyTestPolicy,
+	}
+
+	conn, err := relay.ConnectionFromKeyset(fetch, keysetTestKey, relay.NewConnectionArguments(nil), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conn.Edges) != 2 || queries[0].Limit != 3 {
+		t.Fatalf("wrong result, got: %v, %+v", conn.Edges, queries[0])
+	}
+}
@@ -0,0 +1,58 @@
+package relay
+
+import (
+	"golang.org/x/net/context"
+)
+
+/*
+A lazily loaded, offset-addressable data source, such as a database table or
+a remote API.
+*/
+type Paginator interface {
+	// Returns the number of items in the whole data source.
+	Count(ctx context.Context) (int, error)
+	// Returns at most `limit` items, starting at `offset`.
+	Fetch(ctx context.Context, offset int, limit int) ([]interface{}, error)
+}
+
+/*
+Returns a connection object for use in GraphQL, loading only the items of
+the requested page from the paginator.
+
+The paginator is counted once, and fetched at most once with the smallest
+window that covers `args`, so callers no longer have to work out
+`SliceStart` and `ArrayLength` for `ConnectionFromArraySlice` themselves.
+Page sizes are checked against the PaginationPolicy in `opts`, if any, and
+cursors the cursor codec cannot decode are rejected with a
+*ConnectionArgumentError wrapping ErrInvalidCursor.
+*/
+func ConnectionFromPaginator(ctx context.Context, paginator Paginator, args ConnectionArguments, opts ...ConnectionOptions) (*Connection, error) {
+	options := mergeConnectionOptions(opts)
+	args, err := options.PaginationPolicy.Apply(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCursors(args, options.cursorCodec()); err != nil {
+		return nil, err
+	}
+
+	count, err := paginator.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta := ArraySliceMetaInfo{
+		SliceStart:  0,
+		ArrayLength: count,
+	}
+	window := newArraySliceWindow(count, args, meta, options)
+
+	slice := []interface{}{}
+	if limit := window.endOffset - window.startOffset; limit > 0 {
+		slice, err = paginator.Fetch(ctx, window.startOffset, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	meta.SliceStart = min(window.startOffset, count)
+	return ConnectionFromArraySlice(slice, args, meta, options), nil
+}
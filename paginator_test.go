@@ -0,0 +1,136 @@
+package relay_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+type paginatorTestFetch struct {
+	Offset int
+	Limit  int
+}
+
+// paginatorTestPaginator serves a slice, and records the windows it is asked for.
+type paginatorTestPaginator struct {
+	data    []interface{}
+	fetches []paginatorTestFetch
+	err     error
+}
+
+func (p *paginatorTestPaginator) Count(ctx context.Context) (int, error) {
+	return len(p.data), p.err
+}
+
+func (p *paginatorTestPaginator) Fetch(ctx context.Context, offset int, limit int) ([]interface{}, error) {
+	p.fetches = append(p.fetches, paginatorTestFetch{offset, limit})
+	end := offset + limit
+	if end > len(p.data) {
+		end = len(p.data)
+	}
+	return p.data[offset:end], nil
+}
+
+func TestConnectionFromPaginator_FetchesOnlyTheRequestedWindow(t *testing.T) {
+	paginator := &paginatorTestPaginator{data: arrayConnectionTestLetters}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": "YXJyYXljb25uZWN0aW9uOjA=",
+	})
+
+	result, err := relay.ConnectionFromPaginator(context.Background(), paginator, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := relay.ConnectionFromArray(arrayConnectionTestLetters, args)
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, connection result diff: %v", testutil.Diff(expected, result))
+	}
+	if !reflect.DeepEqual(paginator.fetches, []paginatorTestFetch{{1, 2}}) {
+		t.Fatalf("wrong fetches, got: %v", paginator.fetches)
+	}
+}
+func TestConnectionFromPaginator_MatchesConnectionFromArray(t *testing.T) {
+	cursors := []interface{}{nil, "invalid"}
+	for offset := -1; offset <= 6; offset++ {
+		cursors = append(cursors, string(relay.OffsetToCursor(offset)))
+	}
+	invalidCursors := map[interface{}]bool{
+		"invalid":                        true,
+		string(relay.OffsetToCursor(-1)): true,
+	}
+	sizes := []interface{}{nil, 0, 1, 2, 10}
+
+	for _, after := range cursors {
+		for _, before := range cursors {
+			for _, first := range sizes {
+				for _, last := range sizes {
+					filter := map[string]interface{}{}
+					for name, value := range map[string]interface{}{"after": after, "before": before, "first": first, "last": last} {
+						if value != nil {
+							filter[name] = value
+						}
+					}
+					args := relay.NewConnectionArguments(filter)
+					for _, options := range []relay.ConnectionOptions{{}, {BidirectionalPageInfo: true}} {
+						paginator := &paginatorTestPaginator{data: arrayConnectionTestLetters}
+						result, err := relay.ConnectionFromPaginator(context.Background(), paginator, args, options)
+						if invalidCursors[after] || invalidCursors[before] {
+							// the array helper ignores the cursors the paginator rejects
+							if !errors.Is(err, relay.ErrInvalidCursor) {
+								t.Fatalf("expected ErrInvalidCursor for %v, got: %v", filter, err)
+							}
+							continue
+						}
+						if err != nil {
+							t.Fatalf("unexpected error: %v", err)
+						}
+						expected := relay.ConnectionFromArray(arrayConnectionTestLetters, args, options)
+						if !reflect.DeepEqual(result, expected) {
+							t.Fatalf("wrong result for %v, %+v, connection result diff: %v", filter, options, testutil.Diff(expected, result))
+						}
+						if len(paginator.fetches) > 1 {
+							t.Fatalf("expected at most one fetch for %v, got: %v", filter, paginator.fetches)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+func TestConnectionFromPaginator_ReturnsPaginatorErrors(t *testing.T) {
+	paginator := &paginatorTestPaginator{
+		data: arrayConnectionTestLetters,
+		err:  errors.New("connection refused"),
+	}
+	_, err := relay.ConnectionFromPaginator(context.Background(), paginator, relay.NewConnectionArguments(nil))
+	if err == nil || err.Error() != "connection refused" {
+		t.Fatalf("expected paginator error, got: %v", err)
+	}
+}
+func TestConnectionFromPaginator_RejectsForgedCursors(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paginator := &paginatorTestPaginator{data: arrayConnectionTestLetters}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(relay.OffsetToCursor(1)),
+	})
+	conn, err := relay.ConnectionFromPaginator(context.Background(), paginator, args, relay.ConnectionOptions{
+		CursorCodec: codec,
+	})
+	var argErr *relay.ConnectionArgumentError
+	var signatureErr *relay.CursorSignatureError
+	if !errors.As(err, &argErr) || argErr.Argument != "after" || !errors.Is(err, relay.ErrInvalidCursor) || !errors.As(err, &signatureErr) {
+		t.Fatalf("expected *CursorSignatureError for unsigned cursor, got: %v, %v", conn, err)
+	}
+	if len(paginator.fetches) != 0 {
+		t.Fatalf("expected no fetch, got: %v", paginator.fetches)
+	}
+}
@@ -0,0 +1,292 @@
+package relay
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// A place where a schema departs from the Relay specifications.
+type SchemaViolation struct {
+	Type     string `json:"type"`
+	Field    string `json:"field"`
+	Argument string `json:"argument"`
+	Message  string `json:"message"`
+}
+
+func (v SchemaViolation) String() string {
+	location := v.Type
+	if v.Field != "" {
+		location += "." + v.Field
+	}
+	if v.Argument != "" {
+		location += "(" + v.Argument + ":)"
+	}
+	return location + ": " + v.Message
+}
+
+/*
+Checks a schema against the Relay Cursor Connections specification and the
+Object Identification specification, and returns the violations found, sorted
+by type name.
+
+Every object type whose name ends in `Connection` or `Edge`, the edge types
+of the connections, `PageInfo`, the fields returning connections, and the
+`Node` interface, its implementations and the `node` and `nodes` root fields
+are checked. The types created by ConnectionDefinitions and NewNodeDefinitions
+conform.
+*/
+func ValidateSchema(schema graphql.Schema) []SchemaViolation {
+	v := &schemaValidator{
+		violations:  []SchemaViolation{},
+		cursorTypes: map[string]graphql.Type{},
+	}
+	typeMap := schema.TypeMap()
+	names := []string{}
+	for name := range typeMap {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	edgeTypes := map[string]*graphql.Object{}
+	for _, name := range names {
+		object, ok := typeMap[name].(*graphql.Object)
+		if !ok {
+			continue
+		}
+		if strings.HasSuffix(name, "Connection") {
+			if edgeType := v.validateConnectionType(object); edgeType != nil {
+				edgeTypes[edgeType.Name()] = edgeType
+			}
+		}
+		if strings.HasSuffix(name, "Edge") {
+			edgeTypes[name] = object
+		}
+	}
+	for _, name := range names {
+		if edgeType, ok := edgeTypes[name]; ok {
+			v.validateEdgeType(edgeType)
+		}
+	}
+	if pageInfo, ok := typeMap["PageInfo"].(*graphql.Object); ok {
+		v.validatePageInfoType(pageInfo)
+	}
+	for _, name := range names {
+		v.validateConnectionFields(typeMap[name])
+	}
+	if nodeInterface, ok := typeMap["Node"].(*graphql.Interface); ok {
+		v.validateNodeInterface(schema, nodeInterface, names, typeMap)
+	}
+
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Type < v.violations[j].Type
+	})
+	return v.violations
+}
+
+type schemaValidator struct {
+	violations []SchemaViolation
+	// the named type of the cursors of each connection, by connection name
+	cursorTypes map[string]graphql.Type
+}
+
+func (v *schemaValidator) addf(typeName, field, argument, format string, a ...interface{}) {
+	v.violations = append(v.violations, SchemaViolation{
+		Type:     typeName,
+		Field:    field,
+		Argument: argument,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// Returns the edge type of the connection, if it has one.
+func (v *schemaValidator) validateConnectionType(connection *graphql.Object) *graphql.Object {
+	fields := connection.Fields()
+	name := connection.Name()
+
+	if pageInfo, ok := fields["pageInfo"]; !ok {
+		v.addf(name, "pageInfo", "", "connection types must have a `pageInfo` field")
+	} else if pageInfo.Type.String() != "PageInfo!" {
+		v.addf(name, "pageInfo", "", "must be of type `PageInfo!`, got `%v`", pageInfo.Type)
+	}
+
+	edges, ok := fields["edges"]
+	if !ok {
+		v.addf(name, "edges", "", "connection types must have an `edges` field")
+		return nil
+	}
+	list, ok := unwrapNonNull(edges.Type).(*graphql.List)
+	if !ok {
+		v.addf(name, "edges", "", "must be a list of edge types, got `%v`", edges.Type)
+		return nil
+	}
+	edgeType, ok := unwrapNonNull(list.OfType).(*graphql.Object)
+	if !ok {
+		v.addf(name, "edges", "", "must be a list of edge types, got `%v`", edges.Type)
+		return nil
+	}
+	if cursor, ok := edgeType.Fields()["cursor"]; ok {
+		v.cursorTypes[name] = unwrapNonNull(cursor.Type)
+	}
+	return edgeType
+}
+
+func (v *schemaValidator) validateEdgeType(edge *graphql.Object) {
+	fields := edge.Fields()
+	name := edge.Name()
+
+	if node, ok := fields["node"]; !ok {
+		v.addf(name, "node", "", "edge types must have a `node` field")
+	} else if _, ok := unwrapNonNull(node.Type).(*graphql.List); ok {
+		v.addf(name, "node", "", "must not be a list, got `%v`", node.Type)
+	}
+
+	if cursor, ok := fields["cursor"]; !ok {
+		v.addf(name, "cursor", "", "edge types must have a `cursor` field")
+	} else if _, ok := unwrapNonNull(cursor.Type).(*graphql.Scalar); !ok {
+		v.addf(name, "cursor", "", "must be a scalar serialized as a string, got `%v`", cursor.Type)
+	}
+}
+
+func (v *schemaValidator) validatePageInfoType(pageInfo *graphql.Object) {
+	fields := pageInfo.Fields()
+	for _, fieldName := range []string{"hasPreviousPage", "hasNextPage"} {
+		if field, ok := fields[fieldName]; !ok {
+			v.addf("PageInfo", fieldName, "", "`PageInfo` must have a `%v` field", fieldName)
+		} else if field.Type.String() != "Boolean!" {
+			v.addf("PageInfo", fieldName, "", "must be of type `Boolean!`, got `%v`", field.Type)
+		}
+	}
+	for _, fieldName := range []string{"startCursor", "endCursor"} {
+		if field, ok := fields[fieldName]; !ok {
+			v.addf("PageInfo", fieldName, "", "`PageInfo` must have a `%v` field", fieldName)
+		} else if _, ok := unwrapNonNull(field.Type).(*graphql.Scalar); !ok {
+			v.addf("PageInfo", fieldName, "", "must be a scalar serialized as a string, got `%v`", field.Type)
+		}
+	}
+}
+
+// Checks the arguments of the fields of a type that return connections.
+func (v *schemaValidator) validateConnectionFields(ttype graphql.Type) {
+	var fields graphql.FieldDefinitionMap
+	switch ttype := ttype.(type) {
+	case *graphql.Object:
+		fields = ttype.Fields()
+	case *graphql.Interface:
+		fields = ttype.Fields()
+	default:
+		return
+	}
+	fieldNames := []string{}
+	for fieldName := range fields {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+
+	for _, fieldName := range fieldNames {
+		field := fields[fieldName]
+		connection, ok := unwrapNonNull(field.Type).(*graphql.Object)
+		if !ok || !strings.HasSuffix(connection.Name(), "Connection") {
+			continue
+		}
+		cursorType, ok := v.cursorTypes[connection.Name()]
+		if !ok {
+			cursorType = graphql.String
+		}
+
+		args := map[string]*graphql.Argument{}
+		for _, arg := range field.Args {
+			args[arg.Name()] = arg
+		}
+		for _, argName := range []string{"first", "after", "last", "before"} {
+			arg, ok := args[argName]
+			if !ok {
+				continue
+			}
+			expected := cursorType
+			if argName == "first" || argName == "last" {
+				expected = graphql.Int
+			}
+			if unwrapNonNull(arg.Type) != expected {
+				v.addf(ttype.Name(), fieldName, argName, "must be of type `%v`, got `%v`", expected, arg.Type)
+			}
+		}
+		_, first := args["first"]
+		_, after := args["after"]
+		_, last := args["last"]
+		_, before := args["before"]
+		if !(first && after) && !(last && before) {
+			v.addf(ttype.Name(), fieldName, "", "fields returning connections must take `first` and `after`, or `last` and `before`")
+		}
+	}
+}
+
+func (v *schemaValidator) validateNodeInterface(schema graphql.Schema, nodeInterface *graphql.Interface, names []string, typeMap graphql.TypeMap) {
+	if id, ok := nodeInterface.Fields()["id"]; !ok {
+		v.addf("Node", "id", "", "the `Node` interface must have an `id` field")
+	} else if id.Type.String() != "ID!" {
+		v.addf("Node", "id", "", "must be of type `ID!`, got `%v`", id.Type)
+	}
+
+	for _, name := range names {
+		object, ok := typeMap[name].(*graphql.Object)
+		if !ok || !implementsInterface(object, nodeInterface) {
+			continue
+		}
+		if id, ok := object.Fields()["id"]; ok && id.Type.String() != "ID!" {
+			v.addf(name, "id", "", "types implementing `Node` must have an `id` of type `ID!`, got `%v`", id.Type)
+		}
+	}
+
+	queryType := schema.QueryType()
+	if queryType == nil {
+		return
+	}
+	fields := queryType.Fields()
+	if node, ok := fields["node"]; ok {
+		if node.Type != nodeInterface {
+			v.addf(queryType.Name(), "node", "", "must be of type `Node`, got `%v`", node.Type)
+		}
+		v.validateIDArgument(queryType.Name(), node, "id", "ID!")
+	}
+	if nodes, ok := fields["nodes"]; ok {
+		if nodes.Type.String() != "[Node]!" {
+			v.addf(queryType.Name(), "nodes", "", "must be of type `[Node]!`, got `%v`", nodes.Type)
+		}
+		v.validateIDArgument(queryType.Name(), nodes, "ids", "[ID!]!")
+	}
+}
+
+func (v *schemaValidator) validateIDArgument(typeName string, field *graphql.FieldDefinition, argName string, expected string) {
+	var arg *graphql.Argument
+	for _, a := range field.Args {
+		if a.Name() == argName {
+			arg = a
+		}
+	}
+	if arg == nil {
+		v.addf(typeName, field.Name, argName, "the `%v` field must take an `%v` argument", field.Name, argName)
+	} else if arg.Type.String() != expected {
+		v.addf(typeName, field.Name, argName, "must be of type `%v`, got `%v`", expected, arg.Type)
+	}
+}
+
+func implementsInterface(object *graphql.Object, iface *graphql.Interface) bool {
+	for _, i := range object.Interfaces() {
+		if i == iface {
+			return true
+		}
+	}
+	return false
+}
+
+func unwrapNonNull(ttype graphql.Type) graphql.Type {
+	if nonNull, ok := ttype.(*graphql.NonNull); ok {
+		return nonNull.OfType
+	}
+	return ttype
+}
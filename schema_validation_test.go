@@ -0,0 +1,162 @@
+package relay_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+func TestValidateSchema_AcceptsTheGeneratedTypes(t *testing.T) {
+	nodeDefinitions := relay.NewNodeDefinitions(relay.NodeDefinitionsConfig{
+		IDFetcher: func(id string, info graphql.ResolveInfo, ctx context.Context) (interface{}, error) {
+			return nil, nil
+		},
+	})
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": relay.GlobalIDField("User", nil),
+		},
+		Interfaces: []*graphql.Interface{nodeDefinitions.NodeInterface},
+	})
+	userConnection := relay.ConnectionDefinitions(relay.ConnectionConfig{
+		Name:       "User",
+		NodeType:   userType,
+		TotalCount: true,
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"node":  nodeDefinitions.NodeField,
+				"nodes": nodeDefinitions.NodesField,
+				"users": &graphql.Field{
+					Type: userConnection.ConnectionType,
+					Args: relay.ConnectionArgs,
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	violations := relay.ValidateSchema(schema)
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations, got: %v", violations)
+	}
+}
+func TestValidateSchema_ReportsHandWrittenTypesThatDrift(t *testing.T) {
+	nodeInterface := graphql.NewInterface(graphql.InterfaceConfig{
+		Name: "Node",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.ID,
+			},
+		},
+	})
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.ID,
+			},
+		},
+		Interfaces: []*graphql.Interface{nodeInterface},
+	})
+	userEdge := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserEdge",
+		Fields: graphql.Fields{
+			"node": &graphql.Field{
+				Type: graphql.NewList(userType),
+			},
+		},
+	})
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"hasPreviousPage": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+			},
+			"startCursor": &graphql.Field{
+				Type: graphql.String,
+			},
+			"endCursor": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+	userConnection := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserConnection",
+		Fields: graphql.Fields{
+			"pageInfo": &graphql.Field{
+				Type: pageInfo,
+			},
+			"edges": &graphql.Field{
+				Type: graphql.NewList(userEdge),
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"node": &graphql.Field{
+					Type: nodeInterface,
+					Args: graphql.FieldConfigArgument{
+						"id": &graphql.ArgumentConfig{
+							Type: graphql.ID,
+						},
+					},
+				},
+				"users": &graphql.Field{
+					Type: userConnection,
+					Args: graphql.FieldConfigArgument{
+						"first": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
+					},
+				},
+			},
+		}),
+		Types: []graphql.Type{userType},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []relay.SchemaViolation{
+		{Type: "Node", Field: "id", Message: "must be of type `ID!`, got `ID`"},
+		{Type: "PageInfo", Field: "hasNextPage", Message: "must be of type `Boolean!`, got `Boolean`"},
+		{Type: "Query", Field: "users", Argument: "first", Message: "must be of type `Int`, got `String`"},
+		{Type: "Query", Field: "users", Message: "fields returning connections must take `first` and `after`, or `last` and `before`"},
+		{Type: "Query", Field: "node", Argument: "id", Message: "must be of type `ID!`, got `ID`"},
+		{Type: "User", Field: "id", Message: "types implementing `Node` must have an `id` of type `ID!`, got `ID`"},
+		{Type: "UserConnection", Field: "pageInfo", Message: "must be of type `PageInfo!`, got `PageInfo`"},
+		{Type: "UserEdge", Field: "node", Message: "must not be a list, got `[User]`"},
+		{Type: "UserEdge", Field: "cursor", Message: "edge types must have a `cursor` field"},
+	}
+	violations := relay.ValidateSchema(schema)
+	if !reflect.DeepEqual(violations, expected) {
+		t.Fatalf("wrong violations, diff: %v", testutil.Diff(expected, violations))
+	}
+}
+func TestSchemaViolation_String(t *testing.T) {
+	violation := relay.SchemaViolation{
+		Type:     "Query",
+		Field:    "users",
+		Argument: "first",
+		Message:  "must be of type `Int`, got `String`",
+	}
+	expected := "Query.users(first:): must be of type `Int`, got `String`"
+	if violation.String() != expected {
+		t.Fatalf("wrong string, expected: %v, got: %v", expected, violation.String())
+	}
+}
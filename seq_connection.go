@@ -0,0 +1,163 @@
+package relay
+
+import (
+	"iter"
+)
+
+/*
+Returns a connection object for use in GraphQL from the items of an
+iterator, using their positions in the sequence as offsets, like
+`ConnectionFromArray`.
+
+The sequence is read only as far as the page requires: items up to the
+`after` cursor are skipped, and reading stops at the `before` cursor or once
+`first + 1` items have been taken, the extra item deciding `hasNextPage`.
+Only `last` without `first` requires reading up to `before` or the end of the
+sequence, keeping no more than `last` items in memory. `TotalCount` is left
+nil because the sequence is generally not read to its end.
+
+With `ConnectionOptions.BidirectionalPageInfo`, the items skipped before the
+page and the item found at the `before` cursor also count as previous and
+next pages, so no extra reading is needed.
+*/
+func ConnectionFromSeq[T any](seq iter.Seq[T], args ConnectionArguments, opts ...ConnectionOptions) *Connection {
+	options := mergeConnectionOptions(opts)
+	// like ConnectionFromArray, page sizes are clamped and invalid cursors ignored
+	args = options.PaginationPolicy.clamp(args)
+	conn, _ := connectionFromSeq2(func(yield func(T, error) bool) {
+		for value := range seq {
+			if !yield(value, nil) {
+				return
+			}
+		}
+	}, args, options)
+	return conn
+}
+
+/*
+Like `ConnectionFromSeq`, for sequences that can fail, such as rows streamed
+from a backend. Reading stops at the first non-nil error, which is returned.
+Page sizes are checked against the PaginationPolicy in `opts`, if any, and
+cursors the cursor codec cannot decode are rejected with a
+*ConnectionArgumentError wrapping ErrInvalidCursor.
+*/
+func ConnectionFromSeq2[T any](seq iter.Seq2[T, error], args ConnectionArguments, opts ...ConnectionOptions) (*Connection, error) {
+	options := mergeConnectionOptions(opts)
+	args, err := options.PaginationPolicy.Apply(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCursors(args, options.cursorCodec()); err != nil {
+		return nil, err
+	}
+	return connectionFromSeq2(seq, args, options)
+}
+
+func connectionFromSeq2[T any](seq iter.Seq2[T, error], args ConnectionArguments, options ConnectionOptions) (*Connection, error) {
+	codec := options.cursorCodec()
+
+	startOffset := max(GetOffsetWithDefault(args.After, -1, options)+1, 0)
+	beforeOffset := -1
+	if args.Before != "" {
+		beforeOffset = GetOffsetWithDefault(args.Before, -1, options)
+	}
+	if beforeOffset != -1 && startOffset > beforeOffset {
+		return NewConnection(), nil
+	}
+
+	// the first item kept in `items` is at offset `itemsOffset`
+	items := []T{}
+	itemsOffset := startOffset
+	hasPreviousPage := false
+	hasNextPage := false
+
+	skipped := false
+	reachedBefore := false
+
+	offset := -1
+	for value, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		offset++
+		if offset < startOffset {
+			skipped = true
+			continue
+		}
+		if beforeOffset != -1 && offset >= beforeOffset {
+			reachedBefore = true
+			break
+		}
+		if args.First != -1 && len(items) == args.First {
+			hasNextPage = true
+			break
+		}
+		if args.First == -1 && args.Last != -1 && len(items) == args.Last {
+			if args.Last == 0 {
+				hasPreviousPage = true
+				continue
+			}
+			items = append(items[1:], value)
+			itemsOffset++
+			hasPreviousPage = true
+			continue
+		}
+		items = append(items, value)
+	}
+
+	// like ConnectionFromArraySlice, a page starting past the end is empty
+	if startOffset > offset+1 {
+		return NewConnection(), nil
+	}
+
+	if args.First != -1 && args.Last != -1 && len(items) > args.Last {
+		trimmed := len(items) - args.Last
+		items = items[trimmed:]
+		itemsOffset += trimmed
+		hasPreviousPage = true
+	}
+
+	if options.BidirectionalPageInfo {
+		hasPreviousPage = hasPreviousPage || skipped
+		hasNextPage = hasNextPage || reachedBefore
+	}
+
+	edges := []*Edge{}
+	for index, value := range items {
+		edges = append(edges, &Edge{
+			Cursor: codec.EncodeCursor(itemsOffset + index),
+			Node:   value,
+		})
+	}
+
+	var firstEdgeCursor, lastEdgeCursor ConnectionCursor
+	if len(edges) > 0 {
+		firstEdgeCursor = edges[0].Cursor
+		lastEdgeCursor = edges[len(edges)-1].Cursor
+	}
+
+	conn := NewConnection()
+	conn.Edges = edges
+	conn.PageInfo = PageInfo{
+		StartCursor:     firstEdgeCursor,
+		EndCursor:       lastEdgeCursor,
+		HasPreviousPage: hasPreviousPage,
+		HasNextPage:     hasNextPage,
+	}
+	return conn, nil
+}
+
+/*
+Like `ConnectionFromSeq`, for items received from a channel. The channel may
+not be drained: once the page is complete, the producer should be stopped,
+e.g. by cancelling its context.
+*/
+func ConnectionFromChan[T any](ch <-chan T, args ConnectionArguments, opts ...ConnectionOptions) *Connection {
+	return ConnectionFromSeq(func(yield func(T) bool) {
+		for value := range ch {
+			if !yield(value) {
+				return
+			}
+		}
+	}, args, opts...)
+}
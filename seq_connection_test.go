@@ -0,0 +1,156 @@
+package relay_test
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/relay"
+)
+
+// seqConnectionTestNaturals yields 0, 1, 2, ... forever, counting the items read.
+func seqConnectionTestNaturals(read *int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			*read++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestConnectionFromSeq_StopsReadingEarly(t *testing.T) {
+	read := 0
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(relay.OffsetToCursor(9)),
+	})
+
+	conn := relay.ConnectionFromSeq(seqConnectionTestNaturals(&read), args)
+	expected := []*relay.Edge{
+		&relay.Edge{
+			Node:   10,
+			Cursor: relay.OffsetToCursor(10),
+		},
+		&relay.Edge{
+			Node:   11,
+			Cursor: relay.OffsetToCursor(11),
+		},
+	}
+	if !reflect.DeepEqual(conn.Edges, expected) {
+		t.Fatalf("wrong edges, edges result diff: %v", testutil.Diff(expected, conn.Edges))
+	}
+	if !conn.PageInfo.HasNextPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+	if read != 13 {
+		t.Fatalf("expected 13 items to be read, got: %v", read)
+	}
+}
+func TestConnectionFromSeq_MatchesConnectionFromArray(t *testing.T) {
+	// cursors past the end are left out: there the array helper reports
+	// hasNextPage relative to the cursor, while the sequence sees its end
+	cursors := []interface{}{nil, "invalid"}
+	for offset := 0; offset <= 5; offset++ {
+		cursors = append(cursors, string(relay.OffsetToCursor(offset)))
+	}
+	sizes := []interface{}{nil, 0, 1, 2, 10}
+
+	for _, after := range cursors {
+		for _, before := range cursors {
+			for _, first := range sizes {
+				for _, last := range sizes {
+					filter := map[string]interface{}{}
+					for name, value := range map[string]interface{}{"after": after, "before": before, "first": first, "last": last} {
+						if value != nil {
+							filter[name] = value
+						}
+					}
+					args := relay.NewConnectionArguments(filter)
+					for _, options := range []relay.ConnectionOptions{{}, {BidirectionalPageInfo: true}} {
+						result := relay.ConnectionFromSeq(slices.Values(arrayConnectionTestLetters), args, options)
+						expected := relay.ConnectionFromArray(arrayConnectionTestLetters, args, options)
+						expected.TotalCount = nil
+						if !reflect.DeepEqual(result, expected) {
+							t.Fatalf("wrong result for %v, %+v, connection result diff: %v", filter, options, testutil.Diff(expected, result))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+func TestConnectionFromSeq2_ReturnsSequenceErrors(t *testing.T) {
+	seq := func(yield func(string, error) bool) {
+		if !yield("A", nil) {
+			return
+		}
+		yield("", errors.New("stream closed"))
+	}
+
+	_, err := relay.ConnectionFromSeq2(seq, relay.NewConnectionArguments(nil))
+	if err == nil || err.Error() != "stream closed" {
+		t.Fatalf("expected sequence error, got: %v", err)
+	}
+
+	// the error is not reached when the page is complete before it
+	conn, err := relay.ConnectionFromSeq2(seq, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 0,
+	}))
+	if err != nil || len(conn.Edges) != 0 || !conn.PageInfo.HasNextPage {
+		t.Fatalf("unexpected result: %v, %v", conn, err)
+	}
+}
+func TestConnectionFromChan_ReadsFromChannel(t *testing.T) {
+	ch := make(chan string, len(arrayConnectionTestLetters))
+	for _, letter := range arrayConnectionTestLetters {
+		ch <- letter.(string)
+	}
+	close(ch)
+
+	conn := relay.ConnectionFromChan(ch, relay.NewConnectionArguments(map[string]interface{}{
+		"last": 2,
+	}))
+	if len(conn.Edges) != 2 || conn.Edges[0].Node != "D" || conn.Edges[1].Node != "E" {
+		t.Fatalf("wrong edges, got: %v", conn.Edges)
+	}
+	if !conn.PageInfo.HasPreviousPage || conn.PageInfo.HasNextPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+}
+func TestConnectionFromSeq2_RejectsForgedCursors(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options := relay.ConnectionOptions{
+		CursorCodec: codec,
+	}
+	seq := func(yield func(interface{}, error) bool) {
+		for _, letter := range arrayConnectionTestLetters {
+			if !yield(letter, nil) {
+				return
+			}
+		}
+	}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first":  2,
+		"before": string(relay.OffsetToCursor(3)),
+	})
+	conn, err := relay.ConnectionFromSeq2(seq, args, options)
+	var argErr *relay.ConnectionArgumentError
+	var signatureErr *relay.CursorSignatureError
+	if !errors.As(err, &argErr) || argErr.Argument != "before" || !errors.Is(err, relay.ErrInvalidCursor) || !errors.As(err, &signatureErr) {
+		t.Fatalf("expected *CursorSignatureError for unsigned cursor, got: %v, %v", conn, err)
+	}
+
+	// ConnectionFromSeq cannot return errors, so it ignores the cursor instead
+	conn = relay.ConnectionFromSeq(slices.Values(arrayConnectionTestLetters), args, options)
+	expected := relay.ConnectionFromArray(arrayConnectionTestLetters, args, options)
+	if !reflect.DeepEqual(conn.Edges, expected.Edges) {
+		t.Fatalf("wrong edges, edges result diff: %v", testutil.Diff(expected.Edges, conn.Edges))
+	}
+}
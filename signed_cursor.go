@@ -0,0 +1,146 @@
+package relay
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const signedCursorSeparator = "."
+
+// A secret shared by the server instances that issue and verify cursors.
+// The ID is embedded in every cursor so that the matching key can be found
+// after a rotation.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Returned when a signed cursor is malformed, signed with an unknown key,
+// or its signature does not match.
+type CursorSignatureError struct {
+	Cursor ConnectionCursor
+	Reason string
+}
+
+func (e *CursorSignatureError) Error() string {
+	return fmt.Sprintf("Invalid cursor signature: %v", e.Reason)
+}
+
+/*
+A CursorCodec that appends an HMAC-SHA256 signature to the cursors of another
+codec, so that clients cannot forge cursors.
+
+Cursors are signed with the first key; every key is accepted when verifying,
+so a new key can be put first while cursors signed with older keys are still
+in circulation.
+*/
+type SignedCursorCodec struct {
+	codec CursorCodec
+	keys  []Key
+}
+
+/*
+Returns a SignedCursorCodec that signs the cursors produced by `codec`, or by
+the DefaultCursorCodec if `codec` is nil.
+*/
+func NewSignedCursorCodec(codec CursorCodec, keys ...Key) (*SignedCursorCodec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("At least one signing key is required")
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if key.ID == "" || strings.Contains(key.ID, signedCursorSeparator) {
+			return nil, fmt.Errorf("Invalid signing key ID: %q", key.ID)
+		}
+		if len(key.Secret) == 0 {
+			return nil, fmt.Errorf("Empty secret for signing key %q", key.ID)
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("Duplicate signing key ID: %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+	if codec == nil {
+		codec = DefaultCursorCodec
+	}
+	return &SignedCursorCodec{
+		codec: codec,
+		keys:  keys,
+	}, nil
+}
+
+func (c *SignedCursorCodec) EncodeCursor(offset int) ConnectionCursor {
+	return c.sign(string(c.codec.EncodeCursor(offset)))
+}
+
+func (c *SignedCursorCodec) DecodeCursor(cursor ConnectionCursor) (int, error) {
+	payload, err := c.verify(cursor)
+	if err != nil {
+		return 0, err
+	}
+	return c.codec.DecodeCursor(ConnectionCursor(payload))
+}
+
+// Signs an arbitrary payload, such as the sort keys of a keyset cursor.
+func (c *SignedCursorCodec) EncodePayload(payload []byte) ConnectionCursor {
+	return c.sign(base64.RawURLEncoding.EncodeToString(payload))
+}
+
+func (c *SignedCursorCodec) DecodePayload(cursor ConnectionCursor) ([]byte, error) {
+	payload, err := c.verify(cursor)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, &CursorSignatureError{Cursor: cursor, Reason: "invalid payload"}
+	}
+	return b, nil
+}
+
+func (c *SignedCursorCodec) sign(payload string) ConnectionCursor {
+	key := c.keys[0]
+	signature := signCursorPayload(key, payload)
+	return ConnectionCursor(payload + signedCursorSeparator + key.ID + signedCursorSeparator + signature)
+}
+
+// Returns the payload of a signed cursor, once its signature is checked.
+func (c *SignedCursorCodec) verify(cursor ConnectionCursor) (string, error) {
+	str := string(cursor)
+	sigIndex := strings.LastIndex(str, signedCursorSeparator)
+	if sigIndex == -1 {
+		return "", &CursorSignatureError{Cursor: cursor, Reason: "missing signature"}
+	}
+	keyIndex := strings.LastIndex(str[:sigIndex], signedCursorSeparator)
+	if keyIndex == -1 {
+		return "", &CursorSignatureError{Cursor: cursor, Reason: "missing key ID"}
+	}
+	payload := str[:keyIndex]
+	keyID := str[keyIndex+1 : sigIndex]
+	signature := str[sigIndex+1:]
+
+	var key *Key
+	for i := range c.keys {
+		if c.keys[i].ID == keyID {
+			key = &c.keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return "", &CursorSignatureError{Cursor: cursor, Reason: fmt.Sprintf("unknown key %q", keyID)}
+	}
+	if !hmac.Equal([]byte(signature), []byte(signCursorPayload(*key, payload))) {
+		return "", &CursorSignatureError{Cursor: cursor, Reason: "signature mismatch"}
+	}
+	return payload, nil
+}
+
+func signCursorPayload(key Key, payload string) string {
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(key.ID + signedCursorSeparator + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,119 @@
+package relay_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/relay"
+)
+
+var signedCursorTestOldKey = relay.Key{ID: "k1", Secret: []byte("old secret")}
+var signedCursorTestNewKey = relay.Key{ID: "k2", Secret: []byte("new secret")}
+
+func TestSignedCursorCodec_RoundTripsOffsets(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for offset := 0; offset < 5; offset++ {
+		decoded, err := codec.DecodeCursor(codec.EncodeCursor(offset))
+		if err != nil || decoded != offset {
+			t.Fatalf("wrong offset, expected: %v, got: %v, %v", offset, decoded, err)
+		}
+	}
+}
+func TestSignedCursorCodec_RejectsForgedCursors(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	otherCodec, err := relay.NewSignedCursorCodec(nil, relay.Key{ID: "k2", Secret: []byte("guessed")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cursors := []relay.ConnectionCursor{
+		relay.OffsetToCursor(999999),
+		relay.ConnectionCursor(string(relay.OffsetToCursor(999999)) + ".k2.c2lnbmF0dXJl"),
+		relay.ConnectionCursor(string(relay.OffsetToCursor(999999)) + ".k3.c2lnbmF0dXJl"),
+		otherCodec.EncodeCursor(999999),
+	}
+	for _, cursor := range cursors {
+		_, err := codec.DecodeCursor(cursor)
+		if _, ok := err.(*relay.CursorSignatureError); !ok {
+			t.Fatalf("expected CursorSignatureError for %v, got: %v", cursor, err)
+		}
+	}
+}
+func TestSignedCursorCodec_AcceptsCursorsSignedWithRotatedKeys(t *testing.T) {
+	oldCodec, err := relay.NewSignedCursorCodec(nil, signedCursorTestOldKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey, signedCursorTestOldKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	offset, err := codec.DecodeCursor(oldCodec.EncodeCursor(3))
+	if err != nil || offset != 3 {
+		t.Fatalf("wrong offset, got: %v, %v", offset, err)
+	}
+	if codec.EncodeCursor(3) == oldCodec.EncodeCursor(3) {
+		t.Fatalf("expected new cursors to be signed with the first key")
+	}
+}
+func TestNewSignedCursorCodec_RejectsInvalidKeys(t *testing.T) {
+	keySets := [][]relay.Key{
+		{},
+		{relay.Key{ID: "", Secret: []byte("secret")}},
+		{relay.Key{ID: "a.b", Secret: []byte("secret")}},
+		{relay.Key{ID: "k1"}},
+		{signedCursorTestOldKey, signedCursorTestOldKey},
+	}
+	for _, keys := range keySets {
+		if _, err := relay.NewSignedCursorCodec(nil, keys...); err == nil {
+			t.Fatalf("expected error for keys %v", keys)
+		}
+	}
+}
+func TestConnectionFromArray_RejectsForgedAfterCursor(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options := relay.ConnectionOptions{
+		CursorCodec: codec,
+	}
+
+	args, err := relay.NewValidatedConnectionArguments(map[string]interface{}{
+		"first": 1,
+		"after": string(codec.EncodeCursor(2)),
+	}, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := relay.ConnectionFromArray(arrayConnectionTestLetters, args, options)
+	if len(result.Edges) != 1 || result.Edges[0].Node != "D" {
+		t.Fatalf("wrong result, got: %v", result.Edges)
+	}
+
+	signed := string(codec.EncodeCursor(2))
+	forgedCursors := []string{
+		string(relay.OffsetToCursor(2)),
+		string(relay.OffsetToCursor(3)) + signed[strings.Index(signed, "."):],
+	}
+	for _, cursor := range forgedCursors {
+		_, err := relay.NewValidatedConnectionArguments(map[string]interface{}{
+			"first": 1,
+			"after": cursor,
+		}, options)
+		var argErr *relay.ConnectionArgumentError
+		if !errors.As(err, &argErr) || argErr.Argument != "after" || !errors.Is(err, relay.ErrInvalidCursor) {
+			t.Fatalf("expected ConnectionArgumentError for forged cursor %v, got: %v", cursor, err)
+		}
+		var signatureErr *relay.CursorSignatureError
+		if !errors.As(err, &signatureErr) {
+			t.Fatalf("expected *CursorSignatureError for forged cursor %v, got: %v", cursor, err)
+		}
+	}
+}
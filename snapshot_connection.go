@@ -0,0 +1,230 @@
+package relay
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SNAPSHOT_PREFIX = "snapshot:"
+
+// The bounds of a SnapshotStore whose config leaves them unset.
+const (
+	DefaultMaxSnapshots = 1000
+	DefaultSnapshotTTL  = 15 * time.Minute
+)
+
+// The snapshot a cursor refers to was evicted from the SnapshotStore.
+var ErrSnapshotExpired = errors.New("Snapshot has expired")
+
+/*
+Bounds the snapshots kept by a SnapshotStore.
+
+Snapshots are evicted `TTL` after they were taken, and the oldest ones are
+evicted once there are more than `MaxSnapshots`. Values that are not positive
+are replaced by DefaultMaxSnapshots and DefaultSnapshotTTL, so that a store is
+always bounded. `Now` defaults to time.Now.
+*/
+type SnapshotStoreConfig struct {
+	MaxSnapshots int
+	TTL          time.Duration
+	Now          func() time.Time
+}
+
+/*
+Keeps copies of mutable arrays so that paginating through them is stable.
+
+The first page of a connection captures a snapshot of the array, and its
+cursors carry the random ID of the snapshot; later pages resolve against that
+snapshot, whatever happened to the array since. A SnapshotStore is safe for
+concurrent use.
+*/
+type SnapshotStore struct {
+	config    SnapshotStoreConfig
+	mu        sync.Mutex
+	snapshots map[string]*arraySnapshot
+	order     []string
+}
+
+type arraySnapshot struct {
+	scope   string
+	data    []interface{}
+	takenAt time.Time
+}
+
+func NewSnapshotStore(config SnapshotStoreConfig) *SnapshotStore {
+	if config.MaxSnapshots <= 0 {
+		config.MaxSnapshots = DefaultMaxSnapshots
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultSnapshotTTL
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &SnapshotStore{
+		config:    config,
+		snapshots: map[string]*arraySnapshot{},
+	}
+}
+
+/*
+Like ConnectionFromArray, but without `before` and `after` the array is
+copied into a new snapshot, and with them the snapshot their cursors refer to
+is used instead of `data`.
+
+`scope` identifies who may page through the snapshot, such as the viewer
+together with the field and its arguments; the snapshot is only used for
+requests of the scope it was captured for, so that a client cannot page
+through the snapshots of others.
+
+Returns ErrSnapshotExpired if the snapshot was evicted, and a
+*ConnectionArgumentError wrapping ErrInvalidCursor for cursors that were not
+issued by the store or were issued for another scope. The cursor codec given
+in the options, which must then be a PayloadCursorCodec, signs or encrypts
+the whole snapshot cursor.
+*/
+func (s *SnapshotStore) ConnectionFromArray(scope string, data []interface{}, args ConnectionArguments, opts ...ConnectionOptions) (*Connection, error) {
+	options := mergeConnectionOptions(opts)
+	payloadCodec, err := options.payloadCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	id := ""
+	var invalidCursorErr *ConnectionArgumentError
+	for _, cursor := range []struct {
+		argument string
+		value    ConnectionCursor
+	}{{"after", args.After}, {"before", args.Before}} {
+		if cursor.value == "" {
+			continue
+		}
+		cursorID, _, err := decodeSnapshotCursor(payloadCodec, cursor.value)
+		if err != nil {
+			return nil, &ConnectionArgumentError{Argument: cursor.argument, Value: cursor.value, Err: ErrInvalidCursor, Cause: err}
+		}
+		if id != "" && cursorID != id {
+			return nil, &ConnectionArgumentError{Argument: cursor.argument, Value: cursor.value, Err: ErrInvalidCursor}
+		}
+		id = cursorID
+		invalidCursorErr = &ConnectionArgumentError{Argument: cursor.argument, Value: cursor.value, Err: ErrInvalidCursor}
+	}
+
+	var snapshot []interface{}
+	if id == "" {
+		if id, snapshot, err = s.capture(scope, data); err != nil {
+			return nil, err
+		}
+	} else {
+		if snapshot, err = s.lookup(scope, id); err != nil {
+			if err == ErrInvalidCursor {
+				return nil, invalidCursorErr
+			}
+			return nil, err
+		}
+	}
+
+	codec := snapshotCursorCodec{id: id, codec: payloadCodec}
+	return ConnectionFromArray(snapshot, args, options, ConnectionOptions{CursorCodec: codec}), nil
+}
+
+// Copies the array into a new snapshot, evicting old snapshots.
+func (s *SnapshotStore) capture(scope string, data []interface{}) (string, []interface{}, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(b)
+	snapshot := &arraySnapshot{
+		scope:   scope,
+		data:    append([]interface{}{}, data...),
+		takenAt: s.config.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict()
+	if len(s.order) >= s.config.MaxSnapshots {
+		delete(s.snapshots, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.snapshots[id] = snapshot
+	s.order = append(s.order, id)
+	return id, snapshot.data, nil
+}
+
+// Returns the data of a snapshot, ErrSnapshotExpired if it was evicted, or
+// ErrInvalidCursor if it belongs to another scope.
+func (s *SnapshotStore) lookup(scope string, id string) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict()
+	snapshot, ok := s.snapshots[id]
+	if !ok {
+		return nil, ErrSnapshotExpired
+	}
+	if snapshot.scope != scope {
+		return nil, ErrInvalidCursor
+	}
+	return snapshot.data, nil
+}
+
+// Drops the snapshots older than the TTL; they are ordered by age, so the
+// expired ones are at the start. Must be called with the lock held.
+func (s *SnapshotStore) evict() {
+	now := s.config.Now()
+	for len(s.order) > 0 && now.Sub(s.snapshots[s.order[0]].takenAt) >= s.config.TTL {
+		delete(s.snapshots, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Encodes the snapshot ID and the offset in cursors protected by the payload
+// codec, if any, and only accepts cursors of that snapshot.
+type snapshotCursorCodec struct {
+	id    string
+	codec PayloadCursorCodec
+}
+
+func (c snapshotCursorCodec) EncodeCursor(offset int) ConnectionCursor {
+	str := SNAPSHOT_PREFIX + c.id + ":" + strconv.Itoa(offset)
+	return encodePayloadCursor(c.codec, ConnectionCursor(base64.StdEncoding.EncodeToString([]byte(str))))
+}
+
+func (c snapshotCursorCodec) DecodeCursor(cursor ConnectionCursor) (int, error) {
+	id, offset, err := decodeSnapshotCursor(c.codec, cursor)
+	if err != nil {
+		return 0, err
+	}
+	if id != c.id {
+		return 0, errors.New("Invalid cursor")
+	}
+	return offset, nil
+}
+
+// Returns the snapshot ID and the offset of a cursor.
+func decodeSnapshotCursor(codec PayloadCursorCodec, cursor ConnectionCursor) (string, int, error) {
+	cursor, err := decodePayloadCursor(codec, cursor)
+	if err != nil {
+		return "", 0, err
+	}
+	b, err := base64.StdEncoding.DecodeString(string(cursor))
+	if err != nil || !strings.HasPrefix(string(b), SNAPSHOT_PREFIX) {
+		return "", 0, errors.New("Invalid cursor")
+	}
+	tokens := strings.SplitN(string(b[len(SNAPSHOT_PREFIX):]), ":", 2)
+	if len(tokens) != 2 || tokens[0] == "" {
+		return "", 0, errors.New("Invalid cursor")
+	}
+	offset, err := strconv.Atoi(tokens[1])
+	if err != nil {
+		return "", 0, errors.New("Invalid cursor")
+	}
+	return tokens[0], offset, nil
+}
@@ -0,0 +1,265 @@
+package relay_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/relay"
+)
+
+type snapshotTestClock struct {
+	now time.Time
+}
+
+func (c *snapshotTestClock) Now() time.Time {
+	return c.now
+}
+
+var snapshotTestConfig = relay.SnapshotStoreConfig{
+	MaxSnapshots: 10,
+	TTL:          time.Minute,
+}
+
+func snapshotTestNodes(conn *relay.Connection) []interface{} {
+	nodes := []interface{}{}
+	for _, edge := range conn.Edges {
+		nodes = append(nodes, edge.Node)
+	}
+	return nodes
+}
+
+func TestSnapshotStore_PagesAreStableWhenTheArrayChanges(t *testing.T) {
+	store := relay.NewSnapshotStore(snapshotTestConfig)
+	data := []interface{}{"A", "B", "C", "D", "E"}
+
+	conn, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(snapshotTestNodes(conn), []interface{}{"A", "B"}) {
+		t.Fatalf("wrong first page, got: %v", snapshotTestNodes(conn))
+	}
+
+	// an item is inserted at the start of the array, and another one changed in place
+	data[3] = "X"
+	data = append([]interface{}{"Z"}, data...)
+
+	conn, err = store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(conn.PageInfo.EndCursor),
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(snapshotTestNodes(conn), []interface{}{"C", "D"}) {
+		t.Fatalf("wrong second page, got: %v", snapshotTestNodes(conn))
+	}
+	if !conn.PageInfo.HasNextPage || conn.TotalCount == nil || *conn.TotalCount != 5 {
+		t.Fatalf("wrong connection, got: %+v", conn)
+	}
+
+	// a request without cursors captures a new snapshot
+	conn, err = store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(snapshotTestNodes(conn), []interface{}{"Z", "A"}) {
+		t.Fatalf("wrong first page, got: %v", snapshotTestNodes(conn))
+	}
+}
+func TestSnapshotStore_ReturnsErrorForExpiredSnapshots(t *testing.T) {
+	clock := &snapshotTestClock{now: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := relay.NewSnapshotStore(relay.SnapshotStoreConfig{
+		TTL: time.Minute,
+		Now: clock.Now,
+	})
+	data := []interface{}{"A", "B", "C"}
+	conn, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 1,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 1,
+		"after": string(conn.PageInfo.EndCursor),
+	})
+
+	clock.now = clock.now.Add(30 * time.Second)
+	if _, err := store.ConnectionFromArray("viewer", data, args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+	if _, err := store.ConnectionFromArray("viewer", data, args); !errors.Is(err, relay.ErrSnapshotExpired) {
+		t.Fatalf("expected ErrSnapshotExpired, got: %v", err)
+	}
+}
+func TestSnapshotStore_EvictsTheOldestSnapshots(t *testing.T) {
+	store := relay.NewSnapshotStore(relay.SnapshotStoreConfig{
+		MaxSnapshots: 2,
+	})
+	data := []interface{}{"A", "B", "C"}
+	cursors := []string{}
+	for i := 0; i < 3; i++ {
+		conn, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+			"first": 1,
+		}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cursors = append(cursors, string(conn.PageInfo.EndCursor))
+	}
+
+	_, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"after": cursors[0],
+	}))
+	if !errors.Is(err, relay.ErrSnapshotExpired) {
+		t.Fatalf("expected ErrSnapshotExpired, got: %v", err)
+	}
+	for _, cursor := range cursors[1:] {
+		_, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+			"after": cursor,
+		}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+func TestSnapshotStore_RejectsCursorsNotIssuedByTheStore(t *testing.T) {
+	store := relay.NewSnapshotStore(snapshotTestConfig)
+	data := []interface{}{"A", "B", "C"}
+	first, _ := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(nil))
+	second, _ := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(nil))
+
+	for _, filter := range []map[string]interface{}{
+		{"after": string(relay.OffsetToCursor(1))},
+		{"before": "invalid"},
+		{"after": string(first.PageInfo.StartCursor), "before": string(second.PageInfo.EndCursor)},
+	} {
+		_, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(filter))
+		if !errors.Is(err, relay.ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for %v, got: %v", filter, err)
+		}
+	}
+}
+func TestSnapshotStore_RejectsCursorsOfAnotherScope(t *testing.T) {
+	store := relay.NewSnapshotStore(snapshotTestConfig)
+	alice, err := store.ConnectionFromArray("alice", []interface{}{"alice-secret-1", "alice-secret-2"}, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 1,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, err := store.ConnectionFromArray("bob", []interface{}{"bob-1", "bob-2"}, relay.NewConnectionArguments(map[string]interface{}{
+		"after": string(alice.PageInfo.EndCursor),
+	}))
+	var argErr *relay.ConnectionArgumentError
+	if !errors.As(err, &argErr) || argErr.Argument != "after" || !errors.Is(err, relay.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for a cursor of another scope, got: %v, %v", conn, err)
+	}
+
+	conn, err = store.ConnectionFromArray("alice", nil, relay.NewConnectionArguments(map[string]interface{}{
+		"after": string(alice.PageInfo.EndCursor),
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(snapshotTestNodes(conn), []interface{}{"alice-secret-2"}) {
+		t.Fatalf("wrong page, got: %v", snapshotTestNodes(conn))
+	}
+}
+func TestSnapshotStore_SignsTheWholeCursor(t *testing.T) {
+	codec, err := relay.NewSignedCursorCodec(nil, signedCursorTestNewKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options := relay.ConnectionOptions{
+		CursorCodec: codec,
+	}
+	store := relay.NewSnapshotStore(snapshotTestConfig)
+	data := []interface{}{"A", "B", "C"}
+	conn, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"first": 1,
+	}), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the snapshot ID is kept, but the offset is changed
+	payload, err := codec.DecodePayload(conn.PageInfo.EndCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unsigned, err := base64.StdEncoding.DecodeString(string(payload))
+	if err != nil || !strings.HasPrefix(string(unsigned), relay.SNAPSHOT_PREFIX) {
+		t.Fatalf("wrong payload, got: %q, %v", unsigned, err)
+	}
+	forged := strings.TrimSuffix(string(unsigned), "0") + "1"
+	for _, cursor := range []string{
+		base64.StdEncoding.EncodeToString([]byte(forged)),
+		string(codec.EncodePayload([]byte(forged))) + "x",
+	} {
+		_, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+			"after": cursor,
+		}), options)
+		var signatureErr *relay.CursorSignatureError
+		if !errors.Is(err, relay.ErrInvalidCursor) || !errors.As(err, &signatureErr) {
+			t.Fatalf("expected *CursorSignatureError for %v, got: %v", cursor, err)
+		}
+	}
+
+	conn, err = store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"after": string(conn.PageInfo.EndCursor),
+	}), options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(snapshotTestNodes(conn), []interface{}{"B", "C"}) {
+		t.Fatalf("wrong page, got: %v", snapshotTestNodes(conn))
+	}
+}
+func TestSnapshotStore_BoundsTheZeroConfig(t *testing.T) {
+	clock := &snapshotTestClock{now: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := relay.NewSnapshotStore(relay.SnapshotStoreConfig{
+		Now: clock.Now,
+	})
+	data := []interface{}{"A", "B", "C"}
+	cursors := []string{}
+	for i := 0; i <= relay.DefaultMaxSnapshots; i++ {
+		conn, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+			"first": 1,
+		}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cursors = append(cursors, string(conn.PageInfo.EndCursor))
+	}
+
+	_, err := store.ConnectionFromArray("viewer", data, relay.NewConnectionArguments(map[string]interface{}{
+		"after": cursors[0],
+	}))
+	if !errors.Is(err, relay.ErrSnapshotExpired) {
+		t.Fatalf("expected ErrSnapshotExpired for the oldest snapshot, got: %v", err)
+	}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"after": cursors[len(cursors)-1],
+	})
+	if _, err := store.ConnectionFromArray("viewer", data, args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock.now = clock.now.Add(relay.DefaultSnapshotTTL)
+	if _, err := store.ConnectionFromArray("viewer", data, args); !errors.Is(err, relay.ErrSnapshotExpired) {
+		t.Fatalf("expected ErrSnapshotExpired after the default TTL, got: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Package sqlconnection builds Relay connections from tables of a database/sql
+database.
+
+Table, column and order names are inserted into the generated SQL as they
+are, so they must come from code, never from client input; values are always
+passed as query arguments.
+*/
+package sqlconnection
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/relay"
+	"golang.org/x/net/context"
+)
+
+// Selects the bind parameter syntax of the database driver.
+type PlaceholderFormat int
+
+const (
+	// `?`, as used by MySQL and SQLite.
+	QuestionPlaceholders PlaceholderFormat = iota
+	// `$1`, `$2`, ..., as used by PostgreSQL.
+	DollarPlaceholders
+)
+
+// Scans the current row into a node.
+type ScanFn func(rows *sql.Rows) (interface{}, error)
+
+type Order struct {
+	Column     string `json:"column"`
+	Descending bool   `json:"descending"`
+}
+
+/*
+Describes the rows of a connection.
+
+`Where` is an optional SQL condition; its placeholders are bound to `Args`
+and must be numbered from 1 when using DollarPlaceholders. `OrderBy` is
+required, and must define a total order, e.g. by ending with the primary key,
+for pagination to be stable. `Key` is only needed for keyset pagination, and must return the
+values of the `OrderBy` columns of a node.
+*/
+type Query struct {
+	Table        string            `json:"table"`
+	Columns      []string          `json:"columns"`
+	Where        string            `json:"where"`
+	Args         []interface{}     `json:"args"`
+	OrderBy      []Order           `json:"orderBy"`
+	Placeholders PlaceholderFormat `json:"placeholders"`
+	Scan         ScanFn            `json:"-"`
+	Key          relay.KeysetKeyFn `json:"-"`
+}
+
+type paginator struct {
+	db    *sql.DB
+	query Query
+}
+
+/*
+Returns a relay.Paginator that counts the rows of the query with
+`SELECT COUNT(*)` and fetches them with `LIMIT` and `OFFSET`.
+*/
+func NewPaginator(db *sql.DB, query Query) relay.Paginator {
+	return &paginator{
+		db:    db,
+		query: query,
+	}
+}
+
+func (p *paginator) Count(ctx context.Context) (int, error) {
+	b := newQueryBuilder(p.query)
+	b.write("SELECT COUNT(*) FROM " + p.query.Table)
+	b.writeWhere(nil)
+
+	count := 0
+	if err := p.db.QueryRowContext(ctx, b.String(), b.args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (p *paginator) Fetch(ctx context.Context, offset int, limit int) ([]interface{}, error) {
+	b := newQueryBuilder(p.query)
+	b.writeSelect()
+	b.writeWhere(nil)
+	b.writeOrderBy(false)
+	b.write(" LIMIT " + b.bind(limit) + " OFFSET " + b.bind(offset))
+	return queryNodes(ctx, p.db, p.query, b)
+}
+
+/*
+Returns a connection object for the rows of the query, paginated by offset.
+*/
+func ConnectionFromQuery(ctx context.Context, db *sql.DB, query Query, args relay.ConnectionArguments, opts ...relay.ConnectionOptions) (*relay.Connection, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	return relay.ConnectionFromPaginator(ctx, NewPaginator(db, query), args, opts...)
+}
+
+/*
+Returns a connection object for the rows of the query, paginated by the
+values of the `OrderBy` columns, which stays stable when rows are inserted or
+deleted between requests. See relay.ConnectionFromKeyset.
+*/
+func ConnectionFromQueryKeyset(ctx context.Context, db *sql.DB, query Query, args relay.ConnectionArguments, opts ...relay.ConnectionOptions) (*relay.Connection, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	if query.Key == nil {
+		return nil, errors.New("Keyset pagination requires Key")
+	}
+	fetch := func(keyset relay.KeysetQuery) ([]interface{}, error) {
+		for _, keys := range [][]interface{}{keyset.After, keyset.Before} {
+			if keys != nil && len(keys) != len(query.OrderBy) {
+				return nil, errors.New("Invalid cursor")
+			}
+		}
+		b := newQueryBuilder(query)
+		b.writeSelect()
+		conditions := []string{}
+		if keyset.After != nil {
+			conditions = append(conditions, b.keysetCondition(keyset.After, true))
+		}
+		if keyset.Before != nil {
+			conditions = append(conditions, b.keysetCondition(keyset.Before, false))
+		}
+		b.writeWhere(conditions)
+		b.writeOrderBy(keyset.Reverse)
+		if keyset.Limit != -1 {
+			b.write(" LIMIT " + b.bind(keyset.Limit))
+		}
+		return queryNodes(ctx, db, query, b)
+	}
+	return relay.ConnectionFromKeyset(fetch, query.Key, args, opts...)
+}
+
+func validateQuery(query Query) error {
+	if query.Table == "" {
+		return errors.New("Query.Table is required")
+	}
+	if query.Scan == nil {
+		return errors.New("Query.Scan is required")
+	}
+	if len(query.OrderBy) == 0 {
+		return errors.New("Query.OrderBy is required, pages of unordered rows are not stable")
+	}
+	return nil
+}
+
+func queryNodes(ctx context.Context, db *sql.DB, query Query, b *queryBuilder) ([]interface{}, error) {
+	rows, err := db.QueryContext(ctx, b.String(), b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []interface{}{}
+	for rows.Next() {
+		node, err := query.Scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+type queryBuilder struct {
+	strings.Builder
+	query Query
+	args  []interface{}
+}
+
+func newQueryBuilder(query Query) *queryBuilder {
+	b := &queryBuilder{
+		query: query,
+	}
+	b.args = append(b.args, query.Args...)
+	return b
+}
+
+func (b *queryBuilder) write(str string) {
+	b.WriteString(str)
+}
+
+// Adds a query argument, and returns its placeholder.
+func (b *queryBuilder) bind(value interface{}) string {
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			value = i
+		} else if f, err := number.Float64(); err == nil {
+			value = f
+		}
+	}
+	b.args = append(b.args, value)
+	if b.query.Placeholders == DollarPlaceholders {
+		return fmt.Sprintf("$%v", len(b.args))
+	}
+	return "?"
+}
+
+func (b *queryBuilder) writeSelect() {
+	columns := "*"
+	if len(b.query.Columns) > 0 {
+		columns = strings.Join(b.query.Columns, ", ")
+	}
+	b.write("SELECT " + columns + " FROM " + b.query.Table)
+}
+
+func (b *queryBuilder) writeWhere(conditions []string) {
+	if b.query.Where != "" {
+		conditions = append([]string{"(" + b.query.Where + ")"}, conditions...)
+	}
+	if len(conditions) > 0 {
+		b.write(" WHERE " + strings.Join(conditions, " AND "))
+	}
+}
+
+func (b *queryBuilder) writeOrderBy(reverse bool) {
+	if len(b.query.OrderBy) == 0 {
+		return
+	}
+	orders := []string{}
+	for _, order := range b.query.OrderBy {
+		direction := "ASC"
+		if order.Descending != reverse {
+			direction = "DESC"
+		}
+		orders = append(orders, order.Column+" "+direction)
+	}
+	b.write(" ORDER BY " + strings.Join(orders, ", "))
+}
+
+/*
+Returns the condition selecting the rows after (or before) the given keys in
+the order of the query, e.g. `(a > ?) OR (a = ? AND b < ?)` for
+`ORDER BY a ASC, b DESC`.
+*/
+func (b *queryBuilder) keysetCondition(keys []interface{}, after bool) string {
+	alternatives := []string{}
+	for i, order := range b.query.OrderBy {
+		terms := []string{}
+		for j := 0; j < i; j++ {
+			terms = append(terms, b.query.OrderBy[j].Column+" = "+b.bind(keys[j]))
+		}
+		operator := ">"
+		if order.Descending == after {
+			operator = "<"
+		}
+		terms = append(terms, order.Column+" "+operator+" "+b.bind(keys[i]))
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
@@ -0,0 +1,255 @@
+package sqlconnection_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/relay"
+	"github.com/graphql-go/relay/sqlconnection"
+	"golang.org/x/net/context"
+)
+
+type shipRow struct {
+	ID   int64
+	Name string
+}
+
+var shipRows = []*shipRow{
+	&shipRow{1, "X-Wing"},
+	&shipRow{2, "Y-Wing"},
+	&shipRow{3, "A-Wing"},
+	&shipRow{4, "Millenium Falcon"},
+	&shipRow{5, "Home One"},
+}
+
+type fakeQuery struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// fakeDriver answers COUNT(*) queries with the number of ships, and other
+// queries by applying their trailing LIMIT and OFFSET arguments to the ships.
+// Every query it receives is recorded.
+type fakeDriver struct {
+	mu      sync.Mutex
+	queries []fakeQuery
+}
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("sqlconnectiontest", testDriver)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d}, nil
+}
+
+func (d *fakeDriver) reset() []fakeQuery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	queries := d.queries
+	d.queries = nil
+	return queries
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.driver, query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	driver *fakeDriver
+	query  string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.mu.Lock()
+	s.driver.queries = append(s.driver.queries, fakeQuery{s.query, args})
+	s.driver.mu.Unlock()
+
+	if strings.HasPrefix(s.query, "SELECT COUNT(*)") {
+		return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(len(shipRows))}}}, nil
+	}
+	rows := shipRows
+	if strings.HasSuffix(s.query, "LIMIT ? OFFSET ?") {
+		limit := int(args[len(args)-2].(int64))
+		offset := int(args[len(args)-1].(int64))
+		rows = rows[offset:]
+		if limit < len(rows) {
+			rows = rows[:limit]
+		}
+	}
+	values := [][]driver.Value{}
+	for _, row := range rows {
+		values = append(values, []driver.Value{row.ID, row.Name})
+	}
+	return &fakeRows{columns: []string{"id", "name"}, values: values}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func scanShip(rows *sql.Rows) (interface{}, error) {
+	ship := &shipRow{}
+	err := rows.Scan(&ship.ID, &ship.Name)
+	return ship, err
+}
+
+func shipKey(node interface{}) []interface{} {
+	return []interface{}{node.(*shipRow).ID}
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlconnectiontest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testDriver.reset()
+	return db
+}
+
+func shipNames(conn *relay.Connection) []string {
+	names := []string{}
+	for _, edge := range conn.Edges {
+		names = append(names, edge.Node.(*shipRow).Name)
+	}
+	return names
+}
+
+func TestConnectionFromQuery_UsesLimitAndOffset(t *testing.T) {
+	db := openTestDB(t)
+	query := sqlconnection.Query{
+		Table:   "ships",
+		Columns: []string{"id", "name"},
+		Where:   "faction_id = ?",
+		Args:    []interface{}{1},
+		OrderBy: []sqlconnection.Order{{Column: "id"}},
+		Scan:    scanShip,
+	}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"first": 2,
+		"after": string(relay.OffsetToCursor(0)),
+	})
+
+	conn, err := sqlconnection.ConnectionFromQuery(context.Background(), db, query, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := shipNames(conn); !reflect.DeepEqual(names, []string{"Y-Wing", "A-Wing"}) {
+		t.Fatalf("wrong ships, got: %v", names)
+	}
+	if !conn.PageInfo.HasNextPage || conn.TotalCount == nil || *conn.TotalCount != 5 {
+		t.Fatalf("wrong connection, got: %+v, %v", conn.PageInfo, conn.TotalCount)
+	}
+	if conn.Edges[0].Cursor != relay.OffsetToCursor(1) {
+		t.Fatalf("wrong cursor, got: %v", conn.Edges[0].Cursor)
+	}
+
+	expected := []fakeQuery{
+		{"SELECT COUNT(*) FROM ships WHERE (faction_id = ?)", []driver.Value{int64(1)}},
+		{"SELECT id, name FROM ships WHERE (faction_id = ?) ORDER BY id ASC LIMIT ? OFFSET ?", []driver.Value{int64(1), int64(2), int64(1)}},
+	}
+	if queries := testDriver.reset(); !reflect.DeepEqual(queries, expected) {
+		t.Fatalf("wrong queries, expected: %v, got: %v", expected, queries)
+	}
+}
+func TestConnectionFromQueryKeyset_BuildsKeysetConditions(t *testing.T) {
+	db := openTestDB(t)
+	query := sqlconnection.Query{
+		Table: "ships",
+		OrderBy: []sqlconnection.Order{
+			{Column: "name", Descending: true},
+			{Column: "id"},
+		},
+		Placeholders: sqlconnection.DollarPlaceholders,
+		Scan:         scanShip,
+		Key: func(node interface{}) []interface{} {
+			ship := node.(*shipRow)
+			return []interface{}{ship.Name, ship.ID}
+		},
+	}
+	args := relay.NewConnectionArguments(map[string]interface{}{
+		"last":   2,
+		"before": string(relay.KeysToCursor([]interface{}{"X-Wing", 1})),
+	})
+
+	conn, err := sqlconnection.ConnectionFromQueryKeyset(context.Background(), db, query, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the fake driver returns every ship; the helper keeps the last two in reverse
+	if names := shipNames(conn); !reflect.DeepEqual(names, []string{"Y-Wing", "X-Wing"}) {
+		t.Fatalf("wrong ships, got: %v", names)
+	}
+	if !conn.PageInfo.HasPreviousPage {
+		t.Fatalf("wrong page info, got: %+v", conn.PageInfo)
+	}
+	keys, err := relay.CursorToKeys(conn.PageInfo.EndCursor)
+	if err != nil || keys[0] != "X-Wing" {
+		t.Fatalf("wrong cursor keys, got: %v, %v", keys, err)
+	}
+
+	expected := []fakeQuery{
+		{
+			"SELECT * FROM ships WHERE ((name > $1) OR (name = $2 AND id < $3)) ORDER BY name ASC, id DESC LIMIT $4",
+			[]driver.Value{"X-Wing", "X-Wing", int64(1), int64(3)},
+		},
+	}
+	if queries := testDriver.reset(); !reflect.DeepEqual(queries, expected) {
+		t.Fatalf("wrong queries, expected: %v, got: %v", expected, queries)
+	}
+}
+func TestConnectionFromQuery_RejectsIncompleteQueries(t *testing.T) {
+	db := openTestDB(t)
+	args := relay.NewConnectionArguments(nil)
+	orderBy := []sqlconnection.Order{{Column: "id"}}
+
+	if _, err := sqlconnection.ConnectionFromQuery(context.Background(), db, sqlconnection.Query{Scan: scanShip, OrderBy: orderBy}, args); err == nil {
+		t.Fatalf("expected error for missing table")
+	}
+	if _, err := sqlconnection.ConnectionFromQuery(context.Background(), db, sqlconnection.Query{Table: "ships", OrderBy: orderBy}, args); err == nil {
+		t.Fatalf("expected error for missing scan function")
+	}
+	if _, err := sqlconnection.ConnectionFromQuery(context.Background(), db, sqlconnection.Query{Table: "ships", Scan: scanShip}, args); err == nil {
+		t.Fatalf("expected error for missing order")
+	}
+	if _, err := sqlconnection.ConnectionFromQueryKeyset(context.Background(), db, sqlconnection.Query{Table: "ships", Scan: scanShip, Key: shipKey}, args); err == nil {
+		t.Fatalf("expected error for missing order in keyset query")
+	}
+	if _, err := sqlconnection.ConnectionFromQueryKeyset(context.Background(), db, sqlconnection.Query{Table: "ships", Scan: scanShip, OrderBy: orderBy}, args); err == nil {
+		t.Fatalf("expected error for missing key function")
+	}
+	if queries := testDriver.reset(); len(queries) != 0 {
+		t.Fatalf("expected no queries, got: %v", queries)
+	}
+}